@@ -0,0 +1,61 @@
+package command
+
+import (
+	"app/src/config"
+	"app/src/database"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Run executes a maintenance subcommand and returns the process exit code
+func Run(args []string) int {
+	switch args[0] {
+	case "rebuild-summaries":
+		return rebuildSummaries(args[1:], os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\navailable commands: rebuild-summaries\n", args[0])
+		return 2
+	}
+}
+
+// rebuildSummaries recomputes category_spending_summaries and prints the diff report as JSON
+func rebuildSummaries(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("rebuild-summaries", flag.ContinueOnError)
+	userID := flags.String("user", "", "rebuild the summaries of this user id only")
+	dryRun := flags.Bool("dry-run", false, "report the differences without writing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	db := database.Connect(config.DBHost, config.DBName)
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	summaryService := service.NewSummaryService(db, validation.Validator())
+
+	report, err := summaryService.RebuildSummaries(context.Background(), &validation.RebuildSummaries{
+		UserID: *userID,
+		DryRun: *dryRun,
+	})
+	if err != nil {
+		utils.Log.Errorf("Summary rebuild failed: %v", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		utils.Log.Errorf("Failed to write report: %v", err)
+		return 1
+	}
+
+	return 0
+}
@@ -2,33 +2,48 @@ package config
 
 import (
 	"app/src/utils"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 var (
-	IsProd              bool
-	AppHost             string
-	AppPort             int
-	DBHost              string
-	DBUser              string
-	DBPassword          string
-	DBName              string
-	DBPort              int
-	JWTSecret           string
-	JWTAccessExp        int
-	JWTRefreshExp       int
-	JWTResetPasswordExp int
-	JWTVerifyEmailExp   int
-	SMTPHost            string
-	SMTPPort            int
-	SMTPUsername        string
-	SMTPPassword        string
-	EmailFrom           string
-	GoogleClientID      string
-	GoogleClientSecret  string
-	RedirectURL         string
-	N8NWebhookURL       string
+	IsProd                     bool
+	AppHost                    string
+	AppPort                    int
+	AppTimezone                string
+	DBHost                     string
+	DBUser                     string
+	DBPassword                 string
+	DBAppUser                  string
+	DBAppPassword              string
+	DBName                     string
+	DBPort                     int
+	DBTimezone                 string
+	JWTSecret                  string
+	JWTAccessExp               int
+	JWTRefreshExp              int
+	JWTResetPasswordExp        int
+	JWTVerifyEmailExp          int
+	SMTPHost                   string
+	SMTPPort                   int
+	SMTPUsername               string
+	SMTPPassword               string
+	EmailFrom                  string
+	GoogleClientID             string
+	GoogleClientSecret         string
+	RedirectURL                string
+	N8NWebhookURL              string
+	N8NTimeout                 time.Duration
+	SessionClaimCodeExp        int
+	ExpenseExtractor           string
+	ExpenseParserMinConfidence float64
+	ExpenseAutoConfirm         float64
+	Currency                   string
+	AttachmentDir              string
+	AttachmentMaxSize          int64
+	OutboxPollInterval         time.Duration
+	OutboxMaxAttempts          int
 )
 
 func init() {
@@ -39,12 +54,21 @@ func init() {
 	AppHost = viper.GetString("APP_HOST")
 	AppPort = viper.GetInt("APP_PORT")
 
+	// default timezone of users without a preference, Local is the server's
+	viper.SetDefault("APP_TIMEZONE", "Local")
+	AppTimezone = viper.GetString("APP_TIMEZONE")
+
 	// database configuration
 	DBHost = viper.GetString("DB_HOST")
 	DBUser = viper.GetString("DB_USER")
 	DBPassword = viper.GetString("DB_PASSWORD")
+	// role of the server, row-level security applies to it unlike to the owner above
+	DBAppUser = viper.GetString("DB_APP_USER")
+	DBAppPassword = viper.GetString("DB_APP_PASSWORD")
 	DBName = viper.GetString("DB_NAME")
 	DBPort = viper.GetInt("DB_PORT")
+	viper.SetDefault("DB_TIMEZONE", "UTC")
+	DBTimezone = viper.GetString("DB_TIMEZONE")
 
 	// jwt configuration
 	JWTSecret = viper.GetString("JWT_SECRET")
@@ -65,8 +89,36 @@ func init() {
 	GoogleClientSecret = viper.GetString("GOOGLE_CLIENT_SECRET")
 	RedirectURL = viper.GetString("REDIRECT_URL")
 
-	// n8n webhook URL
+	// n8n webhook URL and how long to wait for its answer
 	N8NWebhookURL = viper.GetString("N8N_WEBHOOK_URL")
+	viper.SetDefault("N8N_TIMEOUT_SECONDS", 30)
+	N8NTimeout = time.Duration(viper.GetInt("N8N_TIMEOUT_SECONDS")) * time.Second
+
+	// lifetime of the codes the chat channel sends to let a user claim a session
+	viper.SetDefault("SESSION_CLAIM_CODE_EXP_MINUTES", 10)
+	SessionClaimCodeExp = viper.GetInt("SESSION_CLAIM_CODE_EXP_MINUTES")
+
+	// expense extractor backend: auto || n8n || offline
+	ExpenseExtractor = viper.GetString("EXPENSE_EXTRACTOR")
+	viper.SetDefault("EXPENSE_PARSER_MIN_CONFIDENCE", 0.7)
+	ExpenseParserMinConfidence = viper.GetFloat64("EXPENSE_PARSER_MIN_CONFIDENCE")
+	ExpenseAutoConfirm = viper.GetFloat64("EXPENSE_AUTO_CONFIRM_CONFIDENCE")
+
+	// ISO 4217 currency spendings are recorded and summarised in
+	viper.SetDefault("CURRENCY", "IDR")
+	Currency = viper.GetString("CURRENCY")
+
+	// receipt attachment storage
+	viper.SetDefault("ATTACHMENT_DIR", "./storage/attachments")
+	viper.SetDefault("ATTACHMENT_MAX_SIZE_MB", 5)
+	AttachmentDir = viper.GetString("ATTACHMENT_DIR")
+	AttachmentMaxSize = viper.GetInt64("ATTACHMENT_MAX_SIZE_MB") * 1024 * 1024
+
+	// background processing of outbox events
+	viper.SetDefault("OUTBOX_POLL_INTERVAL_MS", 1000)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	OutboxPollInterval = time.Duration(viper.GetInt("OUTBOX_POLL_INTERVAL_MS")) * time.Millisecond
+	OutboxMaxAttempts = viper.GetInt("OUTBOX_MAX_ATTEMPTS")
 }
 
 func loadConfig() {
@@ -14,6 +14,7 @@ func FiberConfig() fiber.Config {
 		ServerHeader:  "Fiber",
 		AppName:       "Fiber API",
 		ErrorHandler:  utils.ErrorHandler,
+		BodyLimit:     int(AttachmentMaxSize) + 1024*1024, // room for the other form fields
 		JSONEncoder:   sonic.Marshal,
 		JSONDecoder:   sonic.Unmarshal,
 	}
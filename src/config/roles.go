@@ -2,7 +2,9 @@ package config
 
 var allRoles = map[string][]string{
 	"user":  {},
-	"admin": {"getUsers", "manageUsers"},
+	"admin": {"getUsers", "manageUsers", "manageSpendings", "manageExchangeRates", "manageCategories"},
+	// the chat channel (n8n) records spendings of chat sessions and issues their claim codes
+	"channel": {"manageChannelSessions"},
 }
 
 var Roles = getKeys(allRoles)
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type BudgetController struct {
+	BudgetService service.BudgetService
+}
+
+func NewBudgetController(budgetService service.BudgetService) *BudgetController {
+	return &BudgetController{
+		BudgetService: budgetService,
+	}
+}
+
+func (bc *BudgetController) GetBudgets(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	budgets, err := bc.BudgetService.GetBudgets(c, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get budgets successfully",
+			Data:    budgets,
+		})
+}
+
+func (bc *BudgetController) CreateBudget(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.CreateBudget)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	budget, err := bc.BudgetService.CreateBudget(c, req, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create budget successfully",
+			Data:    budget,
+		})
+}
+
+func (bc *BudgetController) UpdateBudget(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.UpdateBudget)
+	budgetID := c.Params("budgetId")
+
+	if _, err := uuid.Parse(budgetID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid budget ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	budget, err := bc.BudgetService.UpdateBudget(c, req, budgetID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Update budget successfully",
+			Data:    budget,
+		})
+}
+
+func (bc *BudgetController) DeleteBudget(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	budgetID := c.Params("budgetId")
+
+	if _, err := uuid.Parse(budgetID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid budget ID")
+	}
+
+	if err := bc.BudgetService.DeleteBudget(c, budgetID, user.ID.String()); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete budget successfully",
+		})
+}
+
+// GetBudgetStatus reports how much of each budget the current week or month used
+func (bc *BudgetController) GetBudgetStatus(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	statuses, err := bc.BudgetService.GetBudgetStatus(c, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get budget status successfully",
+			Data:    statuses,
+		})
+}
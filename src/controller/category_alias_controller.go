@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryAliasController struct {
+	CategoryAliasService service.CategoryAliasService
+}
+
+func NewCategoryAliasController(categoryAliasService service.CategoryAliasService) *CategoryAliasController {
+	return &CategoryAliasController{
+		CategoryAliasService: categoryAliasService,
+	}
+}
+
+func (cc *CategoryAliasController) GetCategoryAliases(c *fiber.Ctx) error {
+	query := &validation.QueryCategoryAlias{
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 10),
+		Search:     c.Query("search", ""),
+		CategoryID: c.Query("category_id", ""),
+	}
+
+	aliases, totalResults, err := cc.CategoryAliasService.GetCategoryAliases(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.CategoryAlias]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Get all category aliases successfully",
+			Results:      aliases,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+func (cc *CategoryAliasController) CreateCategoryAlias(c *fiber.Ctx) error {
+	req := new(validation.CreateCategoryAlias)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	alias, err := cc.CategoryAliasService.CreateCategoryAlias(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create category alias successfully",
+			Data:    alias,
+		})
+}
+
+func (cc *CategoryAliasController) DeleteCategoryAlias(c *fiber.Ctx) error {
+	aliasID := c.Params("aliasId")
+
+	if _, err := uuid.Parse(aliasID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category alias ID")
+	}
+
+	if err := cc.CategoryAliasService.DeleteCategoryAlias(c, aliasID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete category alias successfully",
+		})
+}
+
+func (cc *CategoryAliasController) GetCategoryLabelReviews(c *fiber.Ctx) error {
+	query := &validation.QueryCategoryLabelReview{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 10),
+		Search: c.Query("search", ""),
+	}
+
+	reviews, totalResults, err := cc.CategoryAliasService.GetCategoryLabelReviews(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.CategoryLabelReview]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Get all category label reviews successfully",
+			Results:      reviews,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// ResolveCategoryLabelReview makes a queued label an alias of the given category
+func (cc *CategoryAliasController) ResolveCategoryLabelReview(c *fiber.Ctx) error {
+	req := new(validation.ResolveCategoryLabelReview)
+	reviewID := c.Params("reviewId")
+
+	if _, err := uuid.Parse(reviewID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category label review ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	alias, err := cc.CategoryAliasService.ResolveCategoryLabelReview(c, req, reviewID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Resolve category label review successfully",
+			Data:    alias,
+		})
+}
+
+func (cc *CategoryAliasController) DismissCategoryLabelReview(c *fiber.Ctx) error {
+	reviewID := c.Params("reviewId")
+
+	if _, err := uuid.Parse(reviewID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category label review ID")
+	}
+
+	if err := cc.CategoryAliasService.DismissCategoryLabelReview(c, reviewID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Dismiss category label review successfully",
+		})
+}
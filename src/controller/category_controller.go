@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryController struct {
+	CategoryService service.CategoryService
+}
+
+func NewCategoryController(categoryService service.CategoryService) *CategoryController {
+	return &CategoryController{
+		CategoryService: categoryService,
+	}
+}
+
+func (cc *CategoryController) GetCategories(c *fiber.Ctx) error {
+	query := &validation.QueryCategory{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 10),
+		Search: c.Query("search", ""),
+		Status: c.Query("status", ""),
+		Owner:  c.Query("owner", ""),
+	}
+
+	categories, totalResults, err := cc.CategoryService.GetCategories(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.Category]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Get all categories successfully",
+			Results:      categories,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+func (cc *CategoryController) GetCategoryTree(c *fiber.Ctx) error {
+	query := &validation.QueryCategory{
+		Status: c.Query("status", ""),
+		Owner:  c.Query("owner", ""),
+	}
+
+	tree, err := cc.CategoryService.GetCategoryTree(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get category tree successfully",
+			Data:    tree,
+		})
+}
+
+func (cc *CategoryController) GetCategoryByID(c *fiber.Ctx) error {
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	category, err := cc.CategoryService.GetCategoryByID(c, categoryID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get category successfully",
+			Data:    category,
+		})
+}
+
+func (cc *CategoryController) CreateCategory(c *fiber.Ctx) error {
+	req := new(validation.CreateCategory)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	category, err := cc.CategoryService.CreateCategory(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create category successfully",
+			Data:    category,
+		})
+}
+
+func (cc *CategoryController) UpdateCategory(c *fiber.Ctx) error {
+	req := new(validation.UpdateCategory)
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	return cc.updateCategory(c, req, categoryID, "Update category successfully")
+}
+
+// DeactivateCategory hides a category from the category list and stops new spendings
+// from using it, its existing spendings are kept
+func (cc *CategoryController) DeactivateCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	isActive := false
+	return cc.updateCategory(c, &validation.UpdateCategory{IsActive: &isActive}, categoryID,
+		"Deactivate category successfully")
+}
+
+func (cc *CategoryController) ActivateCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	isActive := true
+	return cc.updateCategory(c, &validation.UpdateCategory{IsActive: &isActive}, categoryID,
+		"Activate category successfully")
+}
+
+func (cc *CategoryController) updateCategory(
+	c *fiber.Ctx, req *validation.UpdateCategory, categoryID, message string,
+) error {
+	category, err := cc.CategoryService.UpdateCategory(c, req, categoryID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: message,
+			Data:    category,
+		})
+}
+
+func (cc *CategoryController) DeleteCategory(c *fiber.Ctx) error {
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	if err := cc.CategoryService.DeleteCategory(c, categoryID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete category successfully",
+		})
+}
+
+// MergeCategory moves the category's spendings and summaries into another category
+// and deletes it
+func (cc *CategoryController) MergeCategory(c *fiber.Ctx) error {
+	req := new(validation.MergeCategory)
+	categoryID := c.Params("categoryId")
+
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid category ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	report, err := cc.CategoryService.MergeCategory(c, req, categoryID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Merge category successfully",
+			Data:    report,
+		})
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryCorrectionController struct {
+	CategoryCorrectionService service.CategoryCorrectionService
+}
+
+func NewCategoryCorrectionController(
+	categoryCorrectionService service.CategoryCorrectionService,
+) *CategoryCorrectionController {
+	return &CategoryCorrectionController{
+		CategoryCorrectionService: categoryCorrectionService,
+	}
+}
+
+func (cc *CategoryCorrectionController) GetCategoryCorrections(c *fiber.Ctx) error {
+	query := &validation.QueryCategoryCorrection{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 10),
+		UserID: c.Params("userId"),
+	}
+
+	corrections, totalResults, err := cc.CategoryCorrectionService.GetCategoryCorrections(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.CategoryCorrection]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Get all category corrections successfully",
+			Results:      corrections,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// GetCategoryCorrectionStats tells how often the user kept the extractor's category
+func (cc *CategoryCorrectionController) GetCategoryCorrectionStats(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	stats, err := cc.CategoryCorrectionService.GetCategoryCorrectionStats(c, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get category correction stats successfully",
+			Data:    stats,
+		})
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CategoryRuleController struct {
+	CategoryRuleService service.CategoryRuleService
+}
+
+func NewCategoryRuleController(categoryRuleService service.CategoryRuleService) *CategoryRuleController {
+	return &CategoryRuleController{
+		CategoryRuleService: categoryRuleService,
+	}
+}
+
+func (cc *CategoryRuleController) GetCategoryRules(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	rules, err := cc.CategoryRuleService.GetCategoryRules(c, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	results := make([]response.CategoryRule, len(rules))
+	for i := range rules {
+		results[i] = categoryRuleResponse(&rules[i])
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get category rules successfully",
+			Data:    results,
+		})
+}
+
+func (cc *CategoryRuleController) CreateCategoryRule(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.CreateCategoryRule)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	rule, err := cc.CategoryRuleService.CreateCategoryRule(c, req, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create category rule successfully",
+			Data:    categoryRuleResponse(rule),
+		})
+}
+
+func (cc *CategoryRuleController) UpdateCategoryRule(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.UpdateCategoryRule)
+	ruleID := c.Params("ruleId")
+
+	if _, err := uuid.Parse(ruleID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid rule ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	rule, err := cc.CategoryRuleService.UpdateCategoryRule(c, req, ruleID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Update category rule successfully",
+			Data:    categoryRuleResponse(rule),
+		})
+}
+
+func (cc *CategoryRuleController) DeleteCategoryRule(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	ruleID := c.Params("ruleId")
+
+	if _, err := uuid.Parse(ruleID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid rule ID")
+	}
+
+	if err := cc.CategoryRuleService.DeleteCategoryRule(c, ruleID, user.ID.String()); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete category rule successfully",
+		})
+}
+
+// PreviewCategoryRule lists the existing spendings the rule would move into its category
+func (cc *CategoryRuleController) PreviewCategoryRule(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	ruleID := c.Params("ruleId")
+
+	if _, err := uuid.Parse(ruleID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid rule ID")
+	}
+
+	spendings, err := cc.CategoryRuleService.PreviewCategoryRule(c, ruleID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.Spending]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Preview category rule successfully",
+			Results:      spendings,
+			TotalResults: int64(len(spendings)),
+		})
+}
+
+func (cc *CategoryRuleController) ApplyCategoryRule(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	ruleID := c.Params("ruleId")
+
+	if _, err := uuid.Parse(ruleID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid rule ID")
+	}
+
+	applied, err := cc.CategoryRuleService.ApplyCategoryRule(c, ruleID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Apply category rule successfully",
+			Data:    response.ApplyCategoryRule{Recategorized: applied},
+		})
+}
+
+func categoryRuleResponse(rule *model.CategoryRule) response.CategoryRule {
+	return response.CategoryRule{
+		CategoryRule: *rule,
+		MinAmount:    rule.MinAmount(),
+		MaxAmount:    rule.MaxAmount(),
+	}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ExchangeRateController struct {
+	ExchangeRateService service.ExchangeRateService
+}
+
+func NewExchangeRateController(exchangeRateService service.ExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{
+		ExchangeRateService: exchangeRateService,
+	}
+}
+
+func (ec *ExchangeRateController) GetExchangeRates(c *fiber.Ctx) error {
+	query := &validation.QueryExchangeRate{
+		Page:     c.QueryInt("page", 1),
+		Limit:    c.QueryInt("limit", 10),
+		Currency: c.Query("currency", ""),
+	}
+
+	rates, totalResults, err := ec.ExchangeRateService.GetExchangeRates(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.ExchangeRate]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Get all exchange rates successfully",
+			Results:      rates,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+func (ec *ExchangeRateController) CreateExchangeRate(c *fiber.Ctx) error {
+	req := new(validation.CreateExchangeRate)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	rate, err := ec.ExchangeRateService.CreateExchangeRate(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create exchange rate successfully",
+			Data:    rate,
+		})
+}
+
+// ImportExchangeRates stores the rates of an uploaded CSV file
+func (ec *ExchangeRateController) ImportExchangeRates(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Upload the exchange rates as a CSV file in the file field")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Cannot open file")
+	}
+	defer file.Close()
+
+	imported, err := ec.ExchangeRateService.ImportExchangeRates(c, file)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Import exchange rates successfully",
+			Data:    response.ImportExchangeRates{Imported: imported},
+		})
+}
+
+func (ec *ExchangeRateController) DeleteExchangeRate(c *fiber.Ctx) error {
+	rateID := c.Params("rateId")
+
+	if _, err := uuid.Parse(rateID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid exchange rate ID")
+	}
+
+	if err := ec.ExchangeRateService.DeleteExchangeRate(c, rateID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete exchange rate successfully",
+		})
+}
@@ -5,164 +5,326 @@ import (
 	"app/src/model"
 	"app/src/response"
 	"app/src/service"
+	"app/src/utils"
 	"app/src/validation"
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
-	"mime"
-	"mime/multipart"
-	"net/http"
-	"net/textproto"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type SpendingController struct {
-	SpendingService service.SpendingService
+	SpendingService   service.SpendingService
+	SessionService    service.SessionService
+	AttachmentService service.AttachmentService
+	ExpenseExtractor  service.ExpenseExtractor
 }
 
-func NewSpendingController(spendingService service.SpendingService) *SpendingController {
+func NewSpendingController(
+	spendingService service.SpendingService, sessionService service.SessionService,
+	attachmentService service.AttachmentService, expenseExtractor service.ExpenseExtractor,
+) *SpendingController {
 	return &SpendingController{
-		SpendingService: spendingService,
+		SpendingService:   spendingService,
+		SessionService:    sessionService,
+		AttachmentService: attachmentService,
+		ExpenseExtractor:  expenseExtractor,
 	}
 }
 
 func (sc *SpendingController) CreateSpending(c *fiber.Ctx) error {
-	webhookURL := config.N8NWebhookURL
-	sessionUserID := c.Get("session_user_id")
-	authHeader := c.Get("Authorization")
+	user, _ := c.Locals("user").(*model.User)
 
-	form, err := c.MultipartForm()
-	if err != nil && err != fiber.ErrUnprocessableEntity {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid form data"})
-	}
+	return sc.createSpending(c, user.ID.String(), spendingCurrency(c, user))
+}
 
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+// CreateChannelSpending records a spending the chat channel received under the chat's
+// session, whether or not a user claimed the session yet
+func (sc *SpendingController) CreateChannelSpending(c *fiber.Ctx) error {
+	userSessionID := c.FormValue("user_session_id")
 
-	if form != nil && form.File != nil && len(form.File["file"]) > 0 {
-		fileHeader := form.File["file"][0]
-		file, err := fileHeader.Open()
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot open file"})
-		}
-		defer file.Close()
-
-		filename := fileHeader.Filename
-		ext := filepath.Ext(filename)
-		mimeType := fileHeader.Header.Get("Content-Type")
+	currency, err := sc.SessionService.GetChannelSessionCurrency(c, userSessionID)
+	if err != nil {
+		return err
+	}
+	if value := c.FormValue("currency"); value != "" {
+		currency = strings.ToUpper(value)
+	}
 
-		if mimeType == "" {
-			mimeType = mime.TypeByExtension(ext)
-			if mimeType == "" {
-				mimeType = "application/octet-stream"
-			}
-		}
+	return sc.createSpending(c, userSessionID, currency)
+}
 
-		partHeader := make(textproto.MIMEHeader)
-		partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
-		partHeader.Set("Content-Type", mimeType)
+// createSpending extracts the spending, or the receipt, from the request and records it
+// under the given session
+func (sc *SpendingController) createSpending(c *fiber.Ctx, userSessionID, currency string) error {
+	extractor, err := sc.expenseExtractor(c)
+	if err != nil {
+		return err
+	}
 
-		part, err := writer.CreatePart(partHeader)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot create form file"})
-		}
+	input, err := expenseInput(c)
+	if err != nil {
+		return err
+	}
+	input.UserSessionID = userSessionID
+	input.Authorization = c.Get("Authorization")
 
-		if _, err := io.Copy(part, file); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot copy file"})
-		}
-	} else if form != nil && form.Value != nil && len(form.Value["text"]) > 0 {
-		text := form.Value["text"][0]
-		if err := writer.WriteField("text", text); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Cannot write text field"})
+	if input.File != nil {
+		if err := sc.AttachmentService.CheckFile(input.File); err != nil {
+			return err
 		}
-	} else {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Either text or file must be provided"})
 	}
 
-	writer.Close()
+	expense, err := extractor.Extract(c.Context(), input)
+	if err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("POST", webhookURL, &buf)
+	datetime, err := transactionDatetime(c, expense)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create request"})
+		return err
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	if sessionUserID != "" {
-		req.Header.Set("session_user_id", sessionUserID)
+	if len(expense.Items) > 1 {
+		return sc.createReceipt(c, input, expense, datetime, currency)
 	}
-	if authHeader != "" {
-		req.Header.Set("Authorization", authHeader)
+	if len(expense.Items) == 1 {
+		expense.Category = expense.Items[0].Category
+		expense.Name = expense.Items[0].Name
+		expense.Amount = expense.Items[0].Amount
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	createSpending := &validation.CreateSpending{
+		UserSessionID: input.UserSessionID,
+		Category:      expense.Category,
+		Amount:        extractedAmount(expense.Amount, currency),
+		Currency:      currency,
+		Name:          expense.Name,
+		Merchant:      expense.Merchant,
+		IsConfirm:     autoConfirm(expense),
+		Datetime:      datetime,
+	}
+	spending, err := sc.SpendingService.CreateSpending(c, createSpending)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send webhook"})
+		return err
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	// Parse response body to get category
-
-	var wr response.WebhookResponse
-	_ = json.Unmarshal(body, &wr) // ignore error, wr.Category will be empty if not found
+	// Keep the receipt so the user can look at it later, the spending stands even if this fails
+	if input.File != nil {
+		if _, err := sc.AttachmentService.CreateAttachment(c, spending, input.File); err != nil {
+			utils.Log.Errorf("Failed to attach receipt to spending %s: %+v", spending.ID, err)
+		}
+	}
 
-	now := time.Now().UTC().Format("2006-01-02T15:04:05Z07:00")
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create spending successfully",
+			Data:    createSpendingResponse(spending),
+		})
+}
 
-	createSpending := &validation.CreateSpending{
-		UserSessionID: sessionUserID,
-		Category:      wr.Category,
-		CategoryID:    "95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5", // Default category ID, should be replaced with actual logic
-		Amount:        float64(wr.Total),
-		Name:          wr.Used,
-		IsConfirm:     true,
-		Datetime:      now,
+// createReceipt stores every line item of a receipt as its own spending
+func (sc *SpendingController) createReceipt(
+	c *fiber.Ctx, input *service.ExpenseInput, expense *service.ExtractedExpense, datetime, currency string,
+) error {
+	createReceipt := &validation.CreateReceipt{
+		UserSessionID: input.UserSessionID,
+		Merchant:      expense.Merchant,
+		Currency:      currency,
+		Datetime:      datetime,
+		IsConfirm:     autoConfirm(expense),
 	}
-	spending, err := sc.SpendingService.CreateSpending(c, createSpending)
+	for _, item := range expense.Items {
+		createReceipt.Items = append(createReceipt.Items, validation.CreateReceiptItem{
+			Category: item.Category,
+			Name:     item.Name,
+			Amount:   extractedAmount(item.Amount, currency),
+		})
+	}
+
+	receipt, err := sc.SpendingService.CreateReceipt(c, createReceipt)
 	if err != nil {
 		return err
 	}
-	// Unmarshal body to map
-	var respMap map[string]interface{}
-	if err := json.Unmarshal(body, &respMap); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to parse webhook response"})
+
+	if input.File != nil {
+		if _, err := sc.AttachmentService.CreateReceiptAttachment(c, receipt, input.File); err != nil {
+			utils.Log.Errorf("Failed to attach receipt file to receipt %s: %+v", receipt.ID, err)
+		}
+	}
+
+	responseReceipt := &response.CreateReceipt{
+		ID:        receipt.ID,
+		Merchant:  receipt.Merchant,
+		Total:     receipt.Total,
+		Date:      receipt.Datetime,
+		Spendings: make([]response.CreateSpending, 0, len(receipt.Spendings)),
+		CreatedAt: &receipt.CreatedAt,
+		UpdatedAt: &receipt.UpdatedAt,
 	}
-	// Inject spending ID
-	respMap["id"] = spending.ID
+	for i := range receipt.Spendings {
+		responseReceipt.Spendings = append(responseReceipt.Spendings, *createSpendingResponse(&receipt.Spendings[i]))
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create receipt successfully",
+			Data:    responseReceipt,
+		})
+}
 
-	responseSpending := &response.CreateSpending{
+func createSpendingResponse(spending *model.Spending) *response.CreateSpending {
+	return &response.CreateSpending{
 		ID:         spending.ID,
 		Name:       spending.Name,
-		Amount:     int64(spending.Amount),
+		Amount:     spending.Amount,
+		BaseAmount: spending.BaseAmount,
 		CategoryID: *spending.CategoryID,
 		Category:   spending.Category,
 		Date:       spending.Datetime,
 		CreatedAt:  &spending.CreatedAt,
 		UpdatedAt:  &spending.UpdatedAt,
 	}
+}
 
-	return c.Status(fiber.StatusCreated).
-		JSON(response.SuccessWithData{
-			Code:    fiber.StatusCreated,
-			Status:  "success",
-			Message: "Create spending successfully",
-			Data:    responseSpending,
-		})
+// extractedAmount rounds an extractor's amount to the minor unit of its currency
+func extractedAmount(value float64, currency string) json.Number {
+	return json.Number(model.MoneyFromFloat(value, currency).String())
+}
 
+// spendingCurrency returns the currency of a new spending: the currency form field,
+// else the user's base currency
+func spendingCurrency(c *fiber.Ctx, user *model.User) string {
+	if currency := c.FormValue("currency"); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	if user.BaseCurrency != "" {
+		return user.BaseCurrency
+	}
+	return config.Currency
+}
+
+// transactionDatetime returns when the spending happened: the datetime form field,
+// else the time the extractor read from the input, else now
+func transactionDatetime(c *fiber.Ctx, expense *service.ExtractedExpense) (string, error) {
+	if value := c.FormValue("datetime"); value != "" {
+		datetime, err := utils.ParseDatetime(value)
+		if err != nil {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Invalid datetime format, use RFC 3339")
+		}
+		return datetime.Format(time.RFC3339), nil
+	}
+
+	if expense.Datetime != nil {
+		return expense.Datetime.Format(time.RFC3339), nil
+	}
+
+	return time.Now().UTC().Format(time.RFC3339), nil
+}
+
+// requestTimezone returns the timezone periods are computed in: the X-Timezone
+// header for a single request, else the user's preference
+func requestTimezone(c *fiber.Ctx, user *model.User) string {
+	if timezone := c.Get("X-Timezone"); timezone != "" {
+		return timezone
+	}
+	return user.Timezone
+}
+
+// autoConfirm reports whether an extracted spending is certain enough to skip the
+// pending state, extractors that report no confidence always need a confirmation
+func autoConfirm(expense *service.ExtractedExpense) bool {
+	return config.ExpenseAutoConfirm > 0 && expense.Confidence >= config.ExpenseAutoConfirm
+}
+
+// expenseExtractor returns the configured extractor. Outside production the
+// X-Expense-Extractor header picks another one for a single request.
+func (sc *SpendingController) expenseExtractor(c *fiber.Ctx) (service.ExpenseExtractor, error) {
+	if name := c.Get("X-Expense-Extractor"); name != "" && !config.IsProd {
+		return service.NewExpenseExtractor(name)
+	}
+	return sc.ExpenseExtractor, nil
+}
+
+// expenseInput reads the uploaded receipt file or the text field of a create request
+func expenseInput(c *fiber.Ctx) (*service.ExpenseInput, error) {
+	form, err := c.MultipartForm()
+	if err != nil && err != fiber.ErrUnprocessableEntity {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid form data")
+	}
+
+	if form != nil && form.File != nil && len(form.File["file"]) > 0 {
+		fileHeader := form.File["file"][0]
+		if fileHeader.Size > config.AttachmentMaxSize {
+			return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge,
+				fmt.Sprintf("File must not be larger than %d bytes", config.AttachmentMaxSize))
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Cannot open file")
+		}
+		defer file.Close()
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Cannot read file")
+		}
+
+		return &service.ExpenseInput{
+			File: &service.ExpenseFile{
+				Filename:    fileHeader.Filename,
+				ContentType: fileHeader.Header.Get("Content-Type"),
+				Content:     content,
+			},
+		}, nil
+	}
+
+	if form != nil && form.Value != nil && len(form.Value["text"]) > 0 {
+		return &service.ExpenseInput{Text: form.Value["text"][0]}, nil
+	}
+
+	return nil, fiber.NewError(fiber.StatusBadRequest, "Either text or file must be provided")
 }
 
 func (sc *SpendingController) GetSpending(c *fiber.Ctx) error {
-	query := &validation.QueryUser{
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpending{
 		Page:   c.QueryInt("page", 1),
 		Limit:  c.QueryInt("limit", 10),
 		Search: c.Query("search", ""),
+		Status: c.Query("status", ""),
+		UserID: user.ID.String(),
 	}
 
+	return sc.listSpendings(c, query, "Get all spendings successfully")
+}
+
+func (sc *SpendingController) GetPendingSpendings(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpending{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 10),
+		Search: c.Query("search", ""),
+		Status: "pending",
+		UserID: user.ID.String(),
+	}
+
+	return sc.listSpendings(c, query, "Get pending spendings successfully")
+}
+
+func (sc *SpendingController) listSpendings(c *fiber.Ctx, query *validation.QuerySpending, message string) error {
 	spendings, totalResults, err := sc.SpendingService.GetSpendings(c, query)
 	if err != nil {
 		return err
@@ -172,7 +334,7 @@ func (sc *SpendingController) GetSpending(c *fiber.Ctx) error {
 		JSON(response.SuccessWithPaginate[model.Spending]{
 			Code:         fiber.StatusOK,
 			Status:       "success",
-			Message:      "Get all spendings successfully",
+			Message:      message,
 			Results:      spendings,
 			Page:         query.Page,
 			Limit:        query.Limit,
@@ -182,8 +344,11 @@ func (sc *SpendingController) GetSpending(c *fiber.Ctx) error {
 }
 
 func (sc *SpendingController) GetCategories(c *fiber.Ctx) error {
-	query := &validation.QueryUser{
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpendingCategory{
 		Search: c.Query("search", ""),
+		UserID: user.ID.String(),
 	}
 
 	categories, totalResults, err := sc.SpendingService.GetCategories(c, query)
@@ -201,11 +366,36 @@ func (sc *SpendingController) GetCategories(c *fiber.Ctx) error {
 		})
 }
 
+func (sc *SpendingController) GetCategoryTree(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpendingCategory{
+		UserID: user.ID.String(),
+	}
+
+	tree, err := sc.SpendingService.GetCategoryTree(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get category tree successfully",
+			Data:    tree,
+		})
+}
+
 func (sc *SpendingController) GetSummaryTotal(c *fiber.Ctx) error {
-	UserSessionID := c.Get("session_user_id")
+	user, _ := c.Locals("user").(*model.User)
+
 	query := &validation.QuerySpendingSummary{
-		PeriodType:    c.Query("period_type", ""),
-		UserSessionID: UserSessionID,
+		PeriodType:  c.Query("period_type", ""),
+		PeriodStart: c.Query("period_start", ""),
+		PeriodEnd:   c.Query("period_end", ""),
+		UserID:      user.ID.String(),
+		Timezone:    requestTimezone(c, user),
 	}
 
 	summary, err := sc.SpendingService.GetSummaryTotal(c, query)
@@ -223,12 +413,16 @@ func (sc *SpendingController) GetSummaryTotal(c *fiber.Ctx) error {
 }
 
 func (sc *SpendingController) GetSummarySpending(c *fiber.Ctx) error {
-	UserSessionID := c.Get("session_user_id")
+	user, _ := c.Locals("user").(*model.User)
+
 	query := &validation.QuerySpendingSummary{
-		Search:        c.Query("search", ""),
-		UserSessionID: UserSessionID,
-		PeriodStart:   c.Query("period_start", ""),
-		PeriodEnd:     c.Query("period_end", ""),
+		Search:      c.Query("search", ""),
+		UserID:      user.ID.String(),
+		PeriodType:  c.Query("period_type", ""),
+		PeriodStart: c.Query("period_start", ""),
+		PeriodEnd:   c.Query("period_end", ""),
+		Rollup:      c.QueryBool("rollup", false),
+		Timezone:    requestTimezone(c, user),
 	}
 
 	summary, totalResults, err := sc.SpendingService.GetSummarySpending(c, query)
@@ -258,3 +452,282 @@ func (sc *SpendingController) GetSummarySpending(c *fiber.Ctx) error {
 			TotalResults: totalResults,
 		})
 }
+
+func (sc *SpendingController) GetTimeseries(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpendingTimeseries{
+		UserID:      user.ID.String(),
+		Bucket:      c.Query("bucket", "day"),
+		PeriodStart: c.Query("period_start", ""),
+		PeriodEnd:   c.Query("period_end", ""),
+		CategoryID:  c.Query("category_id", ""),
+		Rollup:      c.QueryBool("rollup", false),
+		Timezone:    requestTimezone(c, user),
+	}
+
+	timeseries, err := sc.SpendingService.GetTimeseries(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get spending timeseries successfully",
+			Data:    timeseries,
+		})
+}
+
+func (sc *SpendingController) GetComparison(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	query := &validation.QuerySpendingComparison{
+		UserID:     user.ID.String(),
+		PeriodType: c.Query("period_type", "monthly"),
+		Date:       c.Query("date", ""),
+		Rollup:     c.QueryBool("rollup", false),
+		Timezone:   requestTimezone(c, user),
+	}
+
+	comparison, err := sc.SpendingService.GetComparison(c, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get spending comparison successfully",
+			Data:    comparison,
+		})
+}
+
+func (sc *SpendingController) GetSpendingByID(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	spending, err := sc.SpendingService.GetSpendingByID(c, spendingID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get spending successfully",
+			Data:    spending,
+		})
+}
+
+func (sc *SpendingController) UpdateSpending(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.UpdateSpending)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	spending, err := sc.SpendingService.UpdateSpending(c, req, spendingID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Update spending successfully",
+			Data:    spending,
+		})
+}
+
+func (sc *SpendingController) ConfirmSpending(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.UpdateSpending)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	// The corrections are optional, an empty body confirms the spending as extracted
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	spending, err := sc.SpendingService.ConfirmSpending(c, req, spendingID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Confirm spending successfully",
+			Data:    spending,
+		})
+}
+
+func (sc *SpendingController) RejectSpending(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	if err := sc.SpendingService.RejectSpending(c, spendingID, user.ID.String()); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Reject spending successfully",
+		})
+}
+
+func (sc *SpendingController) DeleteSpending(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	if err := sc.SpendingService.DeleteSpending(c, spendingID, user.ID.String()); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.Common{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Delete spending successfully",
+		})
+}
+
+func (sc *SpendingController) GetSessions(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+
+	sessions, err := sc.SessionService.GetSessions(c, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get sessions successfully",
+			Data:    sessions,
+		})
+}
+
+func (sc *SpendingController) ClaimSession(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	req := new(validation.ClaimSession)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	session, err := sc.SessionService.ClaimSession(c, req, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Claim session successfully",
+			Data:    session,
+		})
+}
+
+func (sc *SpendingController) CreateSessionClaimCode(c *fiber.Ctx) error {
+	req := new(validation.CreateSessionClaimCode)
+
+	if err := c.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	code, err := sc.SessionService.CreateClaimCode(c, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusCreated,
+			Status:  "success",
+			Message: "Create claim code successfully",
+			Data:    code,
+		})
+}
+
+func (sc *SpendingController) GetAttachments(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	spendingID := c.Params("spendingId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	attachments, err := sc.AttachmentService.GetAttachments(c, spendingID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: "Get attachments successfully",
+			Data:    attachments,
+		})
+}
+
+func (sc *SpendingController) DownloadAttachment(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*model.User)
+	spendingID := c.Params("spendingId")
+	attachmentID := c.Params("attachmentId")
+
+	if _, err := uuid.Parse(spendingID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid spending ID")
+	}
+
+	if _, err := uuid.Parse(attachmentID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid attachment ID")
+	}
+
+	attachment, file, err := sc.AttachmentService.OpenAttachment(c, spendingID, attachmentID, user.ID.String())
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, strings.ReplaceAll(attachment.Filename, `"`, "")))
+
+	// fasthttp closes the file once the body has been sent
+	return c.Status(fiber.StatusOK).SendStream(file, int(attachment.Size))
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SummaryController struct {
+	SummaryService service.SummaryService
+}
+
+func NewSummaryController(summaryService service.SummaryService) *SummaryController {
+	return &SummaryController{
+		SummaryService: summaryService,
+	}
+}
+
+func (sc *SummaryController) RebuildSummaries(c *fiber.Ctx) error {
+	req := new(validation.RebuildSummaries)
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	report, err := sc.SummaryService.RebuildSummaries(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	message := "Rebuild summaries successfully"
+	if req.DryRun {
+		message = "Summary rebuild dry run successfully"
+	}
+
+	return c.Status(fiber.StatusOK).
+		JSON(response.SuccessWithData{
+			Code:    fiber.StatusOK,
+			Status:  "success",
+			Message: message,
+			Data:    report,
+		})
+}
@@ -4,6 +4,7 @@ import (
 	"app/src/config"
 	"app/src/utils"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -11,11 +12,27 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// Connect opens a connection pool as the app role. The role neither owns the tables nor
+// bypasses row-level security, so the owner policies apply to every query.
 func Connect(dbHost, dbName string) *gorm.DB {
+	return open(dbHost, config.DBAppUser, config.DBAppPassword, dbName)
+}
+
+// ConnectOwner opens a connection pool as the owner of the tables, which migrations and
+// test fixtures use. Settings such as "app.bypass_owner=on" are applied to every
+// connection of the pool.
+func ConnectOwner(dbHost, dbName string, settings ...string) *gorm.DB {
+	return open(dbHost, config.DBUser, config.DBPassword, dbName, settings...)
+}
+
+func open(dbHost, dbUser, dbPassword, dbName string, settings ...string) *gorm.DB {
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Shanghai",
-		dbHost, config.DBUser, config.DBPassword, dbName, config.DBPort,
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s",
+		dbHost, dbUser, dbPassword, dbName, config.DBPort, config.DBTimezone,
 	)
+	if len(settings) > 0 {
+		dsn += fmt.Sprintf(" options='-c %s'", strings.Join(settings, " -c "))
+	}
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger:                 logger.Default.LogMode(logger.Info),
@@ -0,0 +1,7 @@
+DROP POLICY IF EXISTS category_spending_summaries_owner ON category_spending_summaries;
+ALTER TABLE category_spending_summaries NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE category_spending_summaries DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS spendings_owner ON spendings;
+ALTER TABLE spendings NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE spendings DISABLE ROW LEVEL SECURITY;
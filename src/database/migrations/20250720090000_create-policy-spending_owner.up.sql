@@ -0,0 +1,20 @@
+-- Rows are only visible to the sessions listed in app.user_session_ids. Without the
+-- setting no row is visible, work spanning users (the summary worker, rebuilds,
+-- migrations) sets app.bypass_owner to 'on' instead.
+ALTER TABLE spendings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE spendings FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY spendings_owner ON spendings
+    USING (
+        current_setting('app.bypass_owner', true) = 'on'
+        OR user_session_id = ANY (string_to_array(NULLIF(current_setting('app.user_session_ids', true), ''), ',')::uuid[])
+    );
+
+ALTER TABLE category_spending_summaries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE category_spending_summaries FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY category_spending_summaries_owner ON category_spending_summaries
+    USING (
+        current_setting('app.bypass_owner', true) = 'on'
+        OR user_session_id = ANY (string_to_array(NULLIF(current_setting('app.user_session_ids', true), ''), ',')::uuid[])
+    );
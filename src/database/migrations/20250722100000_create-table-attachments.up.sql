@@ -0,0 +1,14 @@
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    spending_id UUID NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT fk_spending
+        FOREIGN KEY (spending_id) REFERENCES spendings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_attachments_spending_id ON attachments(spending_id);
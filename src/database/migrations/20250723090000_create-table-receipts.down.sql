@@ -0,0 +1,13 @@
+DELETE FROM attachments WHERE spending_id IS NULL;
+
+ALTER TABLE attachments
+    DROP CONSTRAINT IF EXISTS chk_attachment_owner,
+    DROP CONSTRAINT IF EXISTS fk_receipt,
+    DROP COLUMN IF EXISTS receipt_id,
+    ALTER COLUMN spending_id SET NOT NULL;
+
+ALTER TABLE spendings
+    DROP CONSTRAINT IF EXISTS fk_receipt,
+    DROP COLUMN IF EXISTS receipt_id;
+
+DROP TABLE IF EXISTS receipts;
@@ -0,0 +1,35 @@
+CREATE TABLE receipts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_session_id UUID NOT NULL,
+    merchant VARCHAR(255),
+    total NUMERIC(12, 2) NOT NULL,
+    datetime TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE receipts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE receipts FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY receipts_owner ON receipts
+    USING (
+        current_setting('app.bypass_owner', true) = 'on'
+        OR user_session_id = ANY (string_to_array(NULLIF(current_setting('app.user_session_ids', true), ''), ',')::uuid[])
+    );
+
+ALTER TABLE spendings
+    ADD COLUMN receipt_id UUID NULL,
+    ADD CONSTRAINT fk_receipt
+        FOREIGN KEY (receipt_id) REFERENCES receipts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_spendings_receipt_id ON spendings(receipt_id);
+
+ALTER TABLE attachments
+    ALTER COLUMN spending_id DROP NOT NULL,
+    ADD COLUMN receipt_id UUID NULL,
+    ADD CONSTRAINT fk_receipt
+        FOREIGN KEY (receipt_id) REFERENCES receipts(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chk_attachment_owner
+        CHECK (spending_id IS NOT NULL OR receipt_id IS NOT NULL);
+
+CREATE INDEX idx_attachments_receipt_id ON attachments(receipt_id);
@@ -0,0 +1,2 @@
+ALTER TABLE category_spending_summaries
+    DROP CONSTRAINT IF EXISTS uq_category_spending_summaries_period;
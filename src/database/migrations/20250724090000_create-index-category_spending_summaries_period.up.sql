@@ -0,0 +1,31 @@
+-- Fold rows duplicated by concurrent inserts into the oldest row of their period
+CREATE TEMPORARY TABLE summary_duplicates AS
+SELECT
+    (ARRAY_AGG(id ORDER BY created_at, id))[1] AS keep_id,
+    user_session_id,
+    category_id,
+    period_type,
+    period_start,
+    SUM(total_amount) AS total_amount
+FROM category_spending_summaries
+GROUP BY user_session_id, category_id, period_type, period_start
+HAVING COUNT(*) > 1;
+
+UPDATE category_spending_summaries s
+SET total_amount = d.total_amount, updated_at = NOW()
+FROM summary_duplicates d
+WHERE s.id = d.keep_id;
+
+DELETE FROM category_spending_summaries s
+USING summary_duplicates d
+WHERE s.user_session_id = d.user_session_id
+  AND s.category_id = d.category_id
+  AND s.period_type = d.period_type
+  AND s.period_start = d.period_start
+  AND s.id <> d.keep_id;
+
+DROP TABLE summary_duplicates;
+
+ALTER TABLE category_spending_summaries
+    ADD CONSTRAINT uq_category_spending_summaries_period
+        UNIQUE (user_session_id, category_id, period_type, period_start);
@@ -0,0 +1,2 @@
+-- IANA timezone the user's periods are computed in, empty uses APP_TIMEZONE
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
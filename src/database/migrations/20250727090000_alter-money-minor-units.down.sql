@@ -0,0 +1,24 @@
+UPDATE outbox_events
+SET payload = jsonb_set(payload, '{amount}', to_jsonb((payload->'amount'->>'minor_units')::bigint / 100))
+WHERE type = 'spending_created' AND jsonb_typeof(payload->'amount') = 'object';
+
+ALTER TABLE category_spending_summaries ADD COLUMN total_amount BIGINT;
+UPDATE category_spending_summaries SET total_amount = total_amount_minor / 100;
+ALTER TABLE category_spending_summaries
+    ALTER COLUMN total_amount SET NOT NULL,
+    DROP COLUMN total_amount_minor,
+    DROP COLUMN total_amount_currency;
+
+ALTER TABLE receipts ADD COLUMN total NUMERIC(12, 2);
+UPDATE receipts SET total = total_minor / 100.0;
+ALTER TABLE receipts
+    ALTER COLUMN total SET NOT NULL,
+    DROP COLUMN total_minor,
+    DROP COLUMN total_currency;
+
+ALTER TABLE spendings ADD COLUMN amount NUMERIC(12, 2);
+UPDATE spendings SET amount = amount_minor / 100.0;
+ALTER TABLE spendings
+    ALTER COLUMN amount SET NOT NULL,
+    DROP COLUMN amount_minor,
+    DROP COLUMN amount_currency;
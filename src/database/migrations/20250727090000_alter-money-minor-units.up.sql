@@ -0,0 +1,39 @@
+-- Amounts move to integer minor units with an ISO 4217 currency code. Existing rows
+-- are rupiah (two decimals). Summaries only kept whole rupiah, run the
+-- rebuild-summaries command afterwards to restore their cents.
+
+ALTER TABLE spendings
+    ADD COLUMN amount_minor BIGINT,
+    ADD COLUMN amount_currency CHAR(3);
+UPDATE spendings SET amount_minor = ROUND(amount * 100), amount_currency = 'IDR';
+ALTER TABLE spendings
+    ALTER COLUMN amount_minor SET NOT NULL,
+    ALTER COLUMN amount_currency SET NOT NULL,
+    DROP COLUMN amount;
+
+ALTER TABLE receipts
+    ADD COLUMN total_minor BIGINT,
+    ADD COLUMN total_currency CHAR(3);
+UPDATE receipts SET total_minor = ROUND(total * 100), total_currency = 'IDR';
+ALTER TABLE receipts
+    ALTER COLUMN total_minor SET NOT NULL,
+    ALTER COLUMN total_currency SET NOT NULL,
+    DROP COLUMN total;
+
+ALTER TABLE category_spending_summaries
+    ADD COLUMN total_amount_minor BIGINT,
+    ADD COLUMN total_amount_currency CHAR(3);
+UPDATE category_spending_summaries SET total_amount_minor = total_amount * 100, total_amount_currency = 'IDR';
+ALTER TABLE category_spending_summaries
+    ALTER COLUMN total_amount_minor SET NOT NULL,
+    ALTER COLUMN total_amount_currency SET NOT NULL,
+    DROP COLUMN total_amount;
+
+-- Queued summary updates carry the amount as money too
+UPDATE outbox_events
+SET payload = jsonb_set(payload, '{amount}', jsonb_build_object(
+    'value', (payload->>'amount')::numeric(20, 2)::text,
+    'currency', 'IDR',
+    'minor_units', (payload->>'amount')::bigint * 100
+))
+WHERE type = 'spending_created' AND jsonb_typeof(payload->'amount') = 'number';
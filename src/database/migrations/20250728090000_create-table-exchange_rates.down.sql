@@ -0,0 +1,8 @@
+ALTER TABLE spendings
+    DROP COLUMN IF EXISTS exchange_rate,
+    DROP COLUMN IF EXISTS base_amount_currency,
+    DROP COLUMN IF EXISTS base_amount_minor;
+
+ALTER TABLE users DROP COLUMN IF EXISTS base_currency;
+
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,26 @@
+-- rate is how much of to_currency one unit of from_currency buys, from effective_date
+-- until the next rate of the pair
+CREATE TABLE exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL CHECK (to_currency <> from_currency),
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    effective_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (from_currency, to_currency, effective_date)
+);
+
+-- Currency summaries and totals are kept in, empty uses CURRENCY
+ALTER TABLE users ADD COLUMN IF NOT EXISTS base_currency VARCHAR(3) NOT NULL DEFAULT '';
+
+-- Spendings keep their original amount next to the amount in the owner's base currency.
+-- Every existing spending is in the default currency, so the base amount is the same.
+ALTER TABLE spendings
+    ADD COLUMN base_amount_minor BIGINT,
+    ADD COLUMN base_amount_currency CHAR(3),
+    ADD COLUMN exchange_rate NUMERIC NOT NULL DEFAULT 1;
+UPDATE spendings SET base_amount_minor = amount_minor, base_amount_currency = amount_currency;
+ALTER TABLE spendings
+    ALTER COLUMN base_amount_minor SET NOT NULL,
+    ALTER COLUMN base_amount_currency SET NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_categories_owner_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS owner_id;
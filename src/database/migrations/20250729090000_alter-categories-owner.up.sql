@@ -0,0 +1,17 @@
+-- Categories without an owner are the defaults everyone sees, the others belong to the
+-- user or unclaimed session that created them
+ALTER TABLE categories ADD COLUMN owner_id UUID;
+CREATE INDEX idx_categories_owner_id ON categories (owner_id);
+
+-- A category only one user (or unclaimed session) ever spent in was created by them,
+-- it becomes theirs. Categories shared by several users or unused stay defaults.
+UPDATE categories SET owner_id = owners.owner_id
+FROM (
+    SELECT s.category_id, MIN(COALESCE(us.user_id, s.user_session_id)::text)::uuid AS owner_id
+    FROM spendings s
+    LEFT JOIN user_sessions us ON us.user_session_id = s.user_session_id
+    WHERE s.category_id IS NOT NULL
+    GROUP BY s.category_id
+    HAVING COUNT(DISTINCT COALESCE(us.user_id, s.user_session_id)) = 1
+) owners
+WHERE categories.id = owners.category_id;
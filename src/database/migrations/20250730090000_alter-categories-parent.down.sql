@@ -0,0 +1,5 @@
+DROP VIEW IF EXISTS category_roots;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+ALTER TABLE categories
+    DROP CONSTRAINT IF EXISTS categories_parent_not_self,
+    DROP COLUMN IF EXISTS parent_id;
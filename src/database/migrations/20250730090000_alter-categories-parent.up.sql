@@ -0,0 +1,21 @@
+-- Categories form a tree, e.g. Transport > Bensin. The service limits its depth and
+-- refuses moves that would make a category its own ancestor.
+ALTER TABLE categories
+    ADD COLUMN parent_id UUID REFERENCES categories (id) ON DELETE RESTRICT,
+    ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- Maps every category to its top-level category, summaries roll up through it. Each
+-- category has exactly one root, so rolled up totals never count a spending twice.
+CREATE VIEW category_roots AS
+WITH RECURSIVE tree AS (
+    SELECT id, id AS root_id, name AS root_name, 1 AS depth
+    FROM categories
+    WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, tree.root_id, tree.root_name, tree.depth + 1
+    FROM categories c
+    JOIN tree ON c.parent_id = tree.id
+    WHERE tree.depth < 10
+)
+SELECT id, root_id, root_name, depth FROM tree;
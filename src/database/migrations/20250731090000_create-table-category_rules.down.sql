@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS category_rules;
+
+ALTER TABLE spendings DROP COLUMN IF EXISTS merchant;
@@ -0,0 +1,25 @@
+-- Rules can match the merchant, so spendings keep the merchant of their receipt
+ALTER TABLE spendings ADD COLUMN merchant VARCHAR(255);
+UPDATE spendings SET merchant = receipts.merchant
+FROM receipts
+WHERE spendings.receipt_id = receipts.id AND receipts.merchant IS NOT NULL;
+
+-- A rule has a pattern, an amount range or both. Amount bounds are in minor units of
+-- amount_currency and inclusive.
+CREATE TABLE category_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    field VARCHAR(20) NOT NULL DEFAULT '' CHECK (field IN ('', 'name', 'description', 'merchant')),
+    match_type VARCHAR(20) NOT NULL DEFAULT '' CHECK (match_type IN ('', 'contains', 'regex')),
+    pattern VARCHAR(255) NOT NULL DEFAULT '',
+    min_amount_minor BIGINT,
+    max_amount_minor BIGINT,
+    amount_currency CHAR(3) NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (pattern <> '' OR min_amount_minor IS NOT NULL OR max_amount_minor IS NOT NULL)
+);
+CREATE INDEX idx_category_rules_user_id ON category_rules (user_id, priority);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS category_label_reviews;
+DROP TABLE IF EXISTS category_aliases;
+
+ALTER TABLE spendings DROP COLUMN IF EXISTS category_label;
@@ -0,0 +1,35 @@
+-- The category label the expense extractor gave, so spendings filed under Other for an
+-- unknown label can move once an admin maps it
+ALTER TABLE spendings ADD COLUMN category_label VARCHAR(255);
+
+-- Labels are stored normalized: without accents, lowercase, punctuation and spaces
+-- collapsed into single spaces. Aliases only point to default categories.
+CREATE TABLE category_aliases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    label VARCHAR(255) NOT NULL,
+    normalized_label VARCHAR(255) NOT NULL UNIQUE,
+    category_id UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX idx_category_aliases_category_id ON category_aliases (category_id);
+
+CREATE TABLE category_label_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    label VARCHAR(255) NOT NULL,
+    normalized_label VARCHAR(255) NOT NULL UNIQUE,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Unknown labels no longer create categories, so the categories of the offline
+-- extractor are defaults. Spendings of labels waiting for review go to Other.
+INSERT INTO categories (name, is_active)
+SELECT name, TRUE
+FROM UNNEST(ARRAY['Food', 'Drink', 'Transport', 'Groceries', 'Bills', 'Health', 'Shopping',
+    'Entertainment', 'Other']) AS defaults (name)
+WHERE NOT EXISTS (
+    SELECT 1 FROM categories WHERE owner_id IS NULL AND LOWER(categories.name) = LOWER(defaults.name)
+);
@@ -0,0 +1,14 @@
+-- Corrections outlive their spending so the extractor's accuracy can still be measured.
+-- original_category_id is the category the spending was created in.
+CREATE TABLE category_corrections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    spending_id UUID UNIQUE REFERENCES spendings (id) ON DELETE SET NULL,
+    raw_text VARCHAR(255) NOT NULL,
+    extractor_label VARCHAR(255) NOT NULL,
+    original_category_id UUID REFERENCES categories (id) ON DELETE SET NULL,
+    category_id UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE INDEX idx_category_corrections_user_id ON category_corrections (user_id, updated_at);
@@ -0,0 +1,13 @@
+-- A user has at most one budget per category and period. Amounts are in minor units of
+-- amount_currency.
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('weekly', 'monthly')),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    amount_currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_budgets_user_category_period UNIQUE (user_id, category_id, period)
+);
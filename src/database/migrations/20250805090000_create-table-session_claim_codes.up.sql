@@ -0,0 +1,10 @@
+-- Codes are stored as their SHA-256 hex digest. A session has at most one code at a
+-- time, issuing a new one replaces it.
+CREATE TABLE session_claim_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_session_id UUID NOT NULL UNIQUE,
+    channel VARCHAR(50) NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
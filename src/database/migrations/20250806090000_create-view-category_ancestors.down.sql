@@ -0,0 +1 @@
+DROP VIEW IF EXISTS category_ancestors;
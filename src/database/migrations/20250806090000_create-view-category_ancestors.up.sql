@@ -0,0 +1,13 @@
+-- Maps every category to itself and to each category above it, rolled up summaries
+-- count a spending in all of them. Only the top-level rows add up to the total.
+CREATE VIEW category_ancestors AS
+WITH RECURSIVE tree AS (
+    SELECT id, id AS ancestor_id, name AS ancestor_name, parent_id IS NULL AS ancestor_is_root, 1 AS depth
+    FROM categories
+    UNION ALL
+    SELECT c.id, tree.ancestor_id, tree.ancestor_name, tree.ancestor_is_root, tree.depth + 1
+    FROM categories c
+    JOIN tree ON c.parent_id = tree.id
+    WHERE tree.depth < 10
+)
+SELECT id, ancestor_id, ancestor_name, ancestor_is_root FROM tree;
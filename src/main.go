@@ -1,16 +1,19 @@
 package main
 
 import (
+	"app/src/command"
 	"app/src/config"
 	"app/src/database"
 	"app/src/middleware"
 	"app/src/router"
+	"app/src/service"
 	"app/src/utils"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -30,20 +33,27 @@ import (
 // @name Authorization
 // @description Example Value: Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
 func main() {
+	// Maintenance subcommands such as rebuild-summaries run instead of the server
+	if len(os.Args) > 1 {
+		os.Exit(command.Run(os.Args[1:]))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	app := setupFiberApp()
 	db := setupDatabase()
 	defer closeDatabase(db)
-	setupRoutes(app, db)
+	summaryWorker := service.NewSummaryWorker(db)
+	setupRoutes(app, db, summaryWorker)
 
 	address := fmt.Sprintf("%s:%d", config.AppHost, config.AppPort)
 
 	// Start server and handle graceful shutdown
+	summaryWorker.Start()
 	serverErrors := make(chan error, 1)
 	go startServer(app, address, serverErrors)
-	handleGracefulShutdown(ctx, app, serverErrors)
+	handleGracefulShutdown(ctx, app, summaryWorker, serverErrors)
 }
 
 func setupFiberApp() *fiber.App {
@@ -66,8 +76,8 @@ func setupDatabase() *gorm.DB {
 	return db
 }
 
-func setupRoutes(app *fiber.App, db *gorm.DB) {
-	router.Routes(app, db)
+func setupRoutes(app *fiber.App, db *gorm.DB, summaryWorker service.SummaryWorker) {
+	router.Routes(app, db, summaryWorker)
 	app.Use(utils.NotFoundHandler)
 }
 
@@ -91,7 +101,9 @@ func closeDatabase(db *gorm.DB) {
 	}
 }
 
-func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-chan error) {
+func handleGracefulShutdown(
+	ctx context.Context, app *fiber.App, summaryWorker service.SummaryWorker, serverErrors <-chan error,
+) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
@@ -107,5 +119,12 @@ func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-
 		utils.Log.Info("Server exiting due to context cancellation")
 	}
 
+	// Requests are finished, let the worker apply the summaries they queued
+	drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := summaryWorker.Shutdown(drainCtx); err != nil {
+		utils.Log.Errorf("Summary worker did not drain in time: %v", err)
+	}
+
 	utils.Log.Info("Server exited")
 }
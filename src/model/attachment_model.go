@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment is a receipt file uploaded together with a spending, or with a
+// receipt when the file produced several spendings
+type Attachment struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SpendingID  *uuid.UUID `gorm:"type:uuid;index" json:"spending_id,omitempty"`
+	ReceiptID   *uuid.UUID `gorm:"type:uuid;index" json:"receipt_id,omitempty"`
+	Filename    string     `gorm:"type:varchar(255);not null" json:"filename"`
+	ContentType string     `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size        int64      `gorm:"type:int8;not null" json:"size"`
+	StorageKey  string     `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt   time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (attachment *Attachment) BeforeCreate(_ *gorm.DB) error {
+	attachment.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Budget caps what a user spends in a category, its subcategories included, per week or
+// month. Periods follow the user's timezone like their summaries do.
+type Budget struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	CategoryID uuid.UUID `gorm:"type:uuid;not null" json:"category_id"`
+	Category   *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Period     string    `gorm:"type:varchar(10);not null" json:"period" example:"monthly"`
+	Amount     Money     `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	CreatedAt  time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (budget *Budget) BeforeCreate(_ *gorm.DB) error {
+	budget.ID = uuid.New()
+	return nil
+}
@@ -7,12 +7,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// Category is a default category everyone sees when OwnerID is nil, otherwise it
+// belongs to the user (or unclaimed session) with that id
 type Category struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
-	IsActive  bool      `gorm:"type:boolean;default:true;not null" json:"is_active"`
-	CreatedAt time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name      string     `gorm:"type:varchar(255);not null" json:"name"`
+	OwnerID   *uuid.UUID `gorm:"type:uuid;index" json:"owner_id"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id"`
+	IsActive  bool       `gorm:"type:boolean;default:true;not null" json:"is_active"`
+	CreatedAt time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
 }
 
 func (category *Category) BeforeCreate(_ *gorm.DB) error {
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// CategoryAlias maps a label the expense extractor gives to a default category. Labels
+// are compared in their normalized form, see NormalizeCategoryLabel.
+type CategoryAlias struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Label           string    `gorm:"type:varchar(255);not null" json:"label" example:"Makanan & Minuman"`
+	NormalizedLabel string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"normalized_label" example:"makanan minuman"`
+	CategoryID      uuid.UUID `gorm:"type:uuid;not null;index" json:"category_id"`
+	Category        *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	CreatedAt       time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (alias *CategoryAlias) BeforeCreate(_ *gorm.DB) error {
+	alias.ID = uuid.New()
+	return nil
+}
+
+// CategoryLabelReview is an extractor label no category or alias matched, waiting for
+// an admin to map it to a category. Label is the first spelling seen.
+type CategoryLabelReview struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Label           string    `gorm:"type:varchar(255);not null" json:"label" example:"Jajan"`
+	NormalizedLabel string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"normalized_label" example:"jajan"`
+	Occurrences     int       `gorm:"not null;default:1" json:"occurrences" example:"3"`
+	LastSeenAt      time.Time `gorm:"type:timestamp with time zone;not null" json:"last_seen_at"`
+	CreatedAt       time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (review *CategoryLabelReview) BeforeCreate(_ *gorm.DB) error {
+	review.ID = uuid.New()
+	return nil
+}
+
+// NormalizeCategoryLabel folds a category label for comparison: accents are dropped,
+// letters lowercased and every run of other characters becomes a single space, so
+// "Café", "cafe" and " CAFE! " are the same label
+func NormalizeCategoryLabel(label string) string {
+	var b strings.Builder
+	space := false
+
+	for _, r := range norm.NFD.String(label) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			space = true
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CategoryCorrection records a user moving an extracted spending out of the category it
+// was given: the spending's name, the label the extractor gave and the category the user
+// chose. A spending keeps its latest correction only, moving it back removes it.
+type CategoryCorrection struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID             uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	SpendingID         *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"spending_id"`
+	RawText            string     `gorm:"type:varchar(255);not null" json:"raw_text" example:"kopi susu"`
+	ExtractorLabel     string     `gorm:"type:varchar(255);not null" json:"extractor_label" example:"Food"`
+	OriginalCategoryID *uuid.UUID `gorm:"type:uuid" json:"original_category_id"`
+	CategoryID         uuid.UUID  `gorm:"type:uuid;not null" json:"category_id"`
+	Category           *Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	CreatedAt          time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (correction *CategoryCorrection) BeforeCreate(_ *gorm.DB) error {
+	correction.ID = uuid.New()
+	return nil
+}
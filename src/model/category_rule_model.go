@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CategoryRule assigns its category to the new spendings of a user it matches. Every
+// condition the rule has must hold: Pattern found in the spending's Field, and the
+// amount within the bounds when the spending is in AmountCurrency.
+type CategoryRule struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	CategoryID     uuid.UUID `gorm:"type:uuid;not null" json:"category_id"`
+	Category       *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Field          string    `gorm:"type:varchar(20);not null;default:''" json:"field,omitempty" example:"merchant"`
+	MatchType      string    `gorm:"type:varchar(20);not null;default:''" json:"match_type,omitempty" example:"contains"`
+	Pattern        string    `gorm:"type:varchar(255);not null;default:''" json:"pattern,omitempty" example:"indomaret"`
+	MinAmountMinor *int64    `gorm:"type:bigint" json:"-"`
+	MaxAmountMinor *int64    `gorm:"type:bigint" json:"-"`
+	AmountCurrency string    `gorm:"type:char(3);not null;default:''" json:"-"`
+	Priority       int       `gorm:"not null;default:0" json:"priority" example:"10"`
+	IsActive       bool      `gorm:"type:boolean;default:true;not null" json:"is_active"`
+	CreatedAt      time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (rule *CategoryRule) BeforeCreate(_ *gorm.DB) error {
+	rule.ID = uuid.New()
+	return nil
+}
+
+// MinAmount returns the lower bound of the amount condition, nil when there is none
+func (rule *CategoryRule) MinAmount() *Money {
+	return rule.bound(rule.MinAmountMinor)
+}
+
+// MaxAmount returns the upper bound of the amount condition, nil when there is none
+func (rule *CategoryRule) MaxAmount() *Money {
+	return rule.bound(rule.MaxAmountMinor)
+}
+
+func (rule *CategoryRule) bound(minor *int64) *Money {
+	if minor == nil {
+		return nil
+	}
+	money := NewMoney(*minor, rule.AmountCurrency)
+	return &money
+}
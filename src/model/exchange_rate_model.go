@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExchangeRate is how much of ToCurrency one unit of FromCurrency buys from
+// EffectiveDate until the next rate of the pair
+type ExchangeRate struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FromCurrency  string    `gorm:"type:char(3);not null" json:"from_currency" example:"SGD"`
+	ToCurrency    string    `gorm:"type:char(3);not null" json:"to_currency" example:"IDR"`
+	Rate          string    `gorm:"type:numeric;not null" json:"rate" example:"12150.25"`
+	EffectiveDate time.Time `gorm:"type:date;not null" json:"effective_date" example:"2025-07-01T00:00:00Z"`
+	CreatedAt     time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (rate *ExchangeRate) BeforeCreate(_ *gorm.DB) error {
+	rate.ID = uuid.New()
+	return nil
+}
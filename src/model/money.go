@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount in the minor unit of its ISO 4217 currency, e.g. cents for
+// USD. Models embed it with a column prefix, so Spending.Amount is stored in the
+// amount_minor and amount_currency columns.
+type Money struct {
+	Minor    int64  `gorm:"type:bigint;not null"`
+	Currency string `gorm:"type:char(3);not null"`
+}
+
+// currencyExponents lists the currencies whose minor unit is not a hundredth
+var currencyExponents = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+var decimalPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// rateDecimals is the precision exchange rates are stored with
+const rateDecimals = 12
+
+// CurrencyExponent returns the number of decimals of a currency's minor unit
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseMoney reads a decimal amount such as "12500.50" exactly. It fails when the
+// amount has more decimals than the currency's minor unit.
+func ParseMoney(value, currency string) (Money, error) {
+	if !decimalPattern.MatchString(value) {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	exponent := CurrencyExponent(currency)
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%s amounts have at most %d decimals", currency, exponent)
+	}
+
+	minor, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %q is out of range", value)
+	}
+
+	return NewMoney(minor, currency), nil
+}
+
+// MoneyFromFloat rounds an amount read by an extractor to the currency's minor unit
+func MoneyFromFloat(value float64, currency string) Money {
+	scale := math.Pow10(CurrencyExponent(currency))
+	return NewMoney(int64(math.Round(value*scale)), currency)
+}
+
+// Convert returns the amount in another currency. The rate is how much of currency one
+// unit of m's currency buys, e.g. 12150.25 for SGD to IDR. The result is rounded half away
+// from zero to the minor unit of currency.
+func (m Money) Convert(currency string, rate *big.Rat) Money {
+	if currency == m.Currency {
+		return m
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Minor), rate)
+
+	shift := CurrencyExponent(currency) - CurrencyExponent(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	minor, remainder := new(big.Int).QuoRem(new(big.Int).Abs(value.Num()), value.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(value.Denom()) >= 0 {
+		minor.Add(minor, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		minor.Neg(minor)
+	}
+
+	return NewMoney(minor.Int64(), currency)
+}
+
+// ParseRate reads a positive exchange rate with at most 12 decimals, e.g. "0.0000823"
+func ParseRate(value string) (*big.Rat, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if !decimalPattern.MatchString(value) || strings.HasPrefix(whole, "-") || len(fraction) > rateDecimals {
+		return nil, fmt.Errorf("invalid exchange rate %q", value)
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("exchange rate %q must be greater than zero", value)
+	}
+
+	return rate, nil
+}
+
+// FormatRate formats an exchange rate as a decimal with at most 12 decimals
+func FormatRate(rate *big.Rat) string {
+	value := rate.FloatString(rateDecimals)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// String formats the amount as a decimal with the currency's number of decimals
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	if exponent == 0 {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+
+	sign, minor := "", m.Minor
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, minor)
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+type moneyJSON struct {
+	Value      string `json:"value" example:"12500.50"`
+	Currency   string `json:"currency" example:"IDR"`
+	MinorUnits int64  `json:"minor_units" example:"1250050"`
+}
+
+// MarshalJSON writes the decimal value next to the exact minor units, so clients
+// never need to parse a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Value: m.String(), Currency: m.Currency, MinorUnits: m.Minor})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if value.Currency == "" {
+		return errors.New("money has no currency")
+	}
+
+	*m = NewMoney(value.MinorUnits, value.Currency)
+	return nil
+}
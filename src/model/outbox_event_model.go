@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is written in the same transaction as the change it describes and
+// handled afterwards by a background worker, so the follow-up work survives
+// restarts and is retried on failure.
+type OutboxEvent struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Type        string          `gorm:"type:varchar(50);not null" json:"type"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Status      string          `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+	Attempts    int             `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError   string          `gorm:"type:text" json:"last_error,omitempty"`
+	AvailableAt time.Time       `gorm:"type:timestamp with time zone;not null" json:"available_at"`
+	ProcessedAt *time.Time      `gorm:"type:timestamp with time zone" json:"processed_at,omitempty"`
+	CreatedAt   time.Time       `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (event *OutboxEvent) BeforeCreate(_ *gorm.DB) error {
+	event.ID = uuid.New()
+	if event.AvailableAt.IsZero() {
+		event.AvailableAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Receipt groups the spendings read from one multi-line-item receipt
+type Receipt struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserSessionID uuid.UUID  `gorm:"type:uuid;not null" json:"user_session_id"`
+	Merchant      string     `gorm:"type:varchar(255)" json:"merchant,omitempty"`
+	Total         Money      `gorm:"embedded;embeddedPrefix:total_" json:"total"`
+	Datetime      time.Time  `gorm:"type:timestamp with time zone;not null" json:"datetime"`
+	CreatedAt     time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+	Spendings     []Spending `gorm:"foreignKey:receipt_id;references:id" json:"spendings,omitempty"`
+}
+
+func (receipt *Receipt) BeforeCreate(_ *gorm.DB) error {
+	receipt.ID = uuid.New()
+	return nil
+}
@@ -12,9 +12,14 @@ type Spending struct {
 	UserSessionID uuid.UUID  `gorm:"type:uuid;not null" json:"user_session_id"`
 	Category      string     `gorm:"type:varchar(255);not null" json:"category"`
 	CategoryID    *uuid.UUID `gorm:"type:uuid" json:"category_id,omitempty"`
+	CategoryLabel string     `gorm:"type:varchar(255)" json:"category_label,omitempty"`
+	ReceiptID     *uuid.UUID `gorm:"type:uuid" json:"receipt_id,omitempty"`
 	Name          string     `gorm:"type:varchar(255);not null" json:"name"`
-	Amount        float64    `gorm:"type:numeric(12,2);not null" json:"amount"`
+	Amount        Money      `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	BaseAmount    Money      `gorm:"embedded;embeddedPrefix:base_amount_" json:"base_amount"`
+	ExchangeRate  string     `gorm:"type:numeric;default:1;not null" json:"exchange_rate" example:"12150.25"`
 	Description   string     `gorm:"type:text" json:"description,omitempty"`
+	Merchant      string     `gorm:"type:varchar(255)" json:"merchant,omitempty"`
 	Datetime      time.Time  `gorm:"type:timestamp with time zone;not null" json:"datetime"`
 	IsConfirm     bool       `gorm:"type:boolean;default:false;not null" json:"is_confirm"`
 	CreatedAt     time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
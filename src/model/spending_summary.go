@@ -12,7 +12,7 @@ type CategorySpendingSummary struct {
 	UserSessionID uuid.UUID  `gorm:"type:uuid;not null" json:"user_session_id"`
 	CategoryID    uuid.UUID  `gorm:"type:uuid;not null" json:"category_id"`
 	Category      string     `gorm:"type:varchar(255);not null" json:"category"`
-	TotalAmount   int64      `gorm:"type:int8;not null" json:"total_amount"`
+	TotalAmount   Money      `gorm:"embedded;embeddedPrefix:total_amount_" json:"total_amount"`
 	PeriodStart   time.Time  `gorm:"type:timestamp with time zone;not null" json:"period_start"`
 	PeriodEnd     time.Time  `gorm:"type:timestamp with time zone;not null" json:"period_end"`
 	PeriodType    string     `gorm:"type:varchar(10);not null" json:"period_type"`
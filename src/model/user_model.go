@@ -14,6 +14,8 @@ type User struct {
 	Password      string    `gorm:"not null" json:"-"`
 	Role          string    `gorm:"default:user;not null" json:"role"`
 	VerifiedEmail bool      `gorm:"default:false;not null" json:"verified_email"`
+	Timezone      string    `gorm:"type:varchar(64);default:'';not null" json:"timezone"`
+	BaseCurrency  string    `gorm:"type:varchar(3);default:'';not null" json:"base_currency"`
 	CreatedAt     time.Time `gorm:"autoCreateTime:milli" json:"-"`
 	UpdatedAt     time.Time `gorm:"autoCreateTime:milli;autoUpdateTime:milli" json:"-"`
 	Token         []Token   `gorm:"foreignKey:user_id;references:id" json:"-"`
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserSession links a chat-channel session id to a registered user, so the
+// spendings recorded under that session belong to the user.
+type UserSession struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	UserSessionID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"user_session_id"`
+	Channel       string    `gorm:"type:varchar(50);not null" json:"channel"`
+	CreatedAt     time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+}
+
+func (session *UserSession) BeforeCreate(_ *gorm.DB) error {
+	session.ID = uuid.New()
+	return nil
+}
+
+// SessionClaimCode is a one-time code the chat channel sends into a session. Whoever
+// can read the chat can claim the session with it until it expires.
+type SessionClaimCode struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserSessionID uuid.UUID `gorm:"type:uuid;not null" json:"user_session_id"`
+	Channel       string    `gorm:"type:varchar(50);not null" json:"channel"`
+	CodeHash      string    `gorm:"type:char(64);not null" json:"-"`
+	ExpiresAt     time.Time `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+	CreatedAt     time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at"`
+}
+
+func (code *SessionClaimCode) BeforeCreate(_ *gorm.DB) error {
+	code.ID = uuid.New()
+	return nil
+}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"app/src/model"
+	"time"
+)
+
+// BudgetStatus is how much of a budget the current period used, in the user's base
+// currency. Remaining is negative once the budget is overspent, Projected extends the
+// spending so far linearly to the end of the period.
+type BudgetStatus struct {
+	model.Budget
+	PeriodStart        time.Time   `json:"period_start"`
+	PeriodEnd          time.Time   `json:"period_end"`
+	Limit              model.Money `json:"limit"`
+	Spent              model.Money `json:"spent"`
+	Remaining          model.Money `json:"remaining"`
+	PercentUsed        float64     `json:"percent_used" example:"62.5"`
+	Projected          model.Money `json:"projected"`
+	ProjectedOverspend model.Money `json:"projected_overspend"`
+}
@@ -0,0 +1,26 @@
+package response
+
+import "github.com/google/uuid"
+
+// CategoryCorrectionStats tells how often a user kept the category the extractor gave.
+// Accuracy is the share of extracted spendings that were not corrected, 0 without any.
+type CategoryCorrectionStats struct {
+	Extracted int64                `json:"extracted" example:"120"`
+	Corrected int64                `json:"corrected" example:"18"`
+	Accuracy  float64              `json:"accuracy" example:"0.85"`
+	Labels    []CategoryLabelStats `json:"labels"`
+}
+
+// CategoryLabelStats are the corrections of the spendings the extractor gave one label
+type CategoryLabelStats struct {
+	Label       string              `json:"label" example:"Food"`
+	Extracted   int64               `json:"extracted" example:"40"`
+	Corrected   int64               `json:"corrected" example:"6"`
+	CorrectedTo []CorrectedCategory `json:"corrected_to"`
+}
+
+type CorrectedCategory struct {
+	CategoryID  uuid.UUID `json:"category_id"`
+	Category    string    `json:"category" example:"Drink"`
+	Corrections int64     `json:"corrections" example:"5"`
+}
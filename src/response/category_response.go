@@ -0,0 +1,23 @@
+package response
+
+import "app/src/model"
+
+// MergeCategory reports what a merge moved into the remaining category
+type MergeCategory struct {
+	Category  model.Category `json:"category"`
+	Spendings int64          `json:"spendings"`
+	Summaries int64          `json:"summaries"`
+}
+
+// CategoryNode is a category with its subcategories
+type CategoryNode struct {
+	model.Category
+	Children []CategoryNode `json:"children"`
+}
+
+// CategoryAlias is a new alias with the number of spendings it moved out of Other,
+// where they waited while their label was unknown
+type CategoryAlias struct {
+	model.CategoryAlias
+	Recategorized int `json:"recategorized"`
+}
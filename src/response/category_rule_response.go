@@ -0,0 +1,14 @@
+package response
+
+import "app/src/model"
+
+// CategoryRule is a rule with the bounds of its amount condition as money
+type CategoryRule struct {
+	model.CategoryRule
+	MinAmount *model.Money `json:"min_amount,omitempty"`
+	MaxAmount *model.Money `json:"max_amount,omitempty"`
+}
+
+type ApplyCategoryRule struct {
+	Recategorized int `json:"recategorized" example:"12"`
+}
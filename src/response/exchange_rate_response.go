@@ -0,0 +1,5 @@
+package response
+
+type ImportExchangeRates struct {
+	Imported int `json:"imported" example:"30"`
+}
@@ -42,10 +42,20 @@ type ErrorDetails struct {
 }
 
 type WebhookResponse struct {
-	Category      string `json:"category"`
-	Used          string `json:"used"`
-	Total         int    `json:"total"`
-	SessionUserID string `json:"session_user_id"`
+	Category      string        `json:"category"`
+	Used          string        `json:"used"`
+	Total         int           `json:"total"`
+	SessionUserID string        `json:"session_user_id"`
+	Merchant      string        `json:"merchant,omitempty"`
+	Datetime      string        `json:"datetime,omitempty"`
+	Items         []WebhookItem `json:"items,omitempty"`
+}
+
+// WebhookItem is one line item of a receipt read by the webhook
+type WebhookItem struct {
+	Category string `json:"category"`
+	Used     string `json:"used"`
+	Total    int    `json:"total"`
 }
 
 type SuccessWithData struct {
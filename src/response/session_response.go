@@ -0,0 +1,16 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionClaimCode is the code the chat channel sends into the session, it is only
+// returned when it is issued
+type SessionClaimCode struct {
+	UserSessionID uuid.UUID `json:"user_session_id"`
+	Channel       string    `json:"channel" example:"whatsapp"`
+	Code          string    `json:"code" example:"K7QX3M9P"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
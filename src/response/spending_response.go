@@ -1,33 +1,85 @@
 package response
 
 import (
+	"app/src/model"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type SummarySpending struct {
-	CategoryID  uuid.UUID  `gorm:"type:uuid;not null" json:"category_id"`
-	Category    string     `gorm:"type:varchar(255);not null" json:"category"`
-	TotalAmount int64      `gorm:"type:int8;not null" json:"total_amount"`
-	PeriodStart time.Time  `gorm:"type:timestamp with time zone;not null" json:"period_start"`
-	PeriodEnd   time.Time  `gorm:"type:timestamp with time zone;not null" json:"period_end"`
-	PeriodType  string     `gorm:"type:varchar(10);not null" json:"period_type"`
-	CreatedAt   *time.Time `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at,omitempty"`
-	UpdatedAt   *time.Time `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at,omitempty"`
+	CategoryID  uuid.UUID   `gorm:"type:uuid;not null" json:"category_id"`
+	Category    string      `gorm:"type:varchar(255);not null" json:"category"`
+	TotalAmount model.Money `json:"total_amount"`
+	PeriodStart time.Time   `gorm:"type:timestamp with time zone;not null" json:"period_start"`
+	PeriodEnd   time.Time   `gorm:"type:timestamp with time zone;not null" json:"period_end"`
+	PeriodType  string      `gorm:"type:varchar(10);not null" json:"period_type"`
+	CreatedAt   *time.Time  `gorm:"type:timestamp with time zone;autoCreateTime" json:"created_at,omitempty"`
+	UpdatedAt   *time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime" json:"updated_at,omitempty"`
 }
 
 type TotalSummarySpending struct {
-	Total int64 `json:"total"`
+	Total model.Money `json:"total"`
+}
+
+type SpendingTimeseries struct {
+	Bucket      string            `json:"bucket"`
+	PeriodStart time.Time         `json:"period_start"`
+	PeriodEnd   time.Time         `json:"period_end"`
+	CategoryID  *uuid.UUID        `json:"category_id,omitempty"`
+	Total       model.Money       `json:"total"`
+	Points      []TimeseriesPoint `json:"points"`
+}
+
+type TimeseriesPoint struct {
+	PeriodStart time.Time   `json:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end"`
+	TotalAmount model.Money `json:"total_amount"`
+}
+
+// SpendingComparison compares the period containing a date with the period before it
+type SpendingComparison struct {
+	PeriodType    string               `json:"period_type"`
+	CurrentStart  time.Time            `json:"current_start"`
+	CurrentEnd    time.Time            `json:"current_end"`
+	PreviousStart time.Time            `json:"previous_start"`
+	PreviousEnd   time.Time            `json:"previous_end"`
+	CurrentTotal  model.Money          `json:"current_total"`
+	PreviousTotal model.Money          `json:"previous_total"`
+	Change        model.Money          `json:"change"`
+	ChangePercent *float64             `json:"change_percent"`
+	Categories    []CategoryComparison `json:"categories"`
+}
+
+type CategoryComparison struct {
+	CategoryID    uuid.UUID   `json:"category_id"`
+	Category      string      `json:"category"`
+	CurrentTotal  model.Money `json:"current_total"`
+	PreviousTotal model.Money `json:"previous_total"`
+	Change        model.Money `json:"change"`
+	ChangePercent *float64    `json:"change_percent"`
+	Share         float64     `json:"share"`
+	Trend         string      `json:"trend" example:"up"`
+}
+
+type CreateReceipt struct {
+	ID        uuid.UUID        `json:"id"`
+	Merchant  string           `json:"merchant,omitempty"`
+	Total     model.Money      `json:"total"`
+	Date      time.Time        `json:"datetime"`
+	Spendings []CreateSpending `json:"spendings"`
+	CreatedAt *time.Time       `json:"created_at,omitempty"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
 }
 
 type CreateSpending struct {
-	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid()" json:"id"`
-	Name       string     `json:"name" validate:"required"`
-	Amount     int64      `json:"amount" validate:"required"`
-	CategoryID uuid.UUID  `json:"category_id" validate:"required"`
-	Category   string     `json:"category" validate:"required"`
-	Date       time.Time  `json:"datetime" validate:"required"`
-	CreatedAt  *time.Time `json:"created_at,omitempty"`
-	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	ID         uuid.UUID   `gorm:"type:uuid;default:gen_random_uuid()" json:"id"`
+	Name       string      `json:"name" validate:"required"`
+	Amount     model.Money `json:"amount" validate:"required"`
+	BaseAmount model.Money `json:"base_amount"`
+	CategoryID uuid.UUID   `json:"category_id" validate:"required"`
+	Category   string      `json:"category" validate:"required"`
+	Date       time.Time   `json:"datetime" validate:"required"`
+	CreatedAt  *time.Time  `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time  `json:"updated_at,omitempty"`
 }
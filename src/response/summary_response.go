@@ -0,0 +1,30 @@
+package response
+
+import (
+	"app/src/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RebuildSummaries struct {
+	DryRun    bool            `json:"dry_run"`
+	Spendings int             `json:"spendings"`
+	Unchanged int             `json:"unchanged"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Deleted   int             `json:"deleted"`
+	Changes   []SummaryChange `json:"changes"`
+}
+
+// SummaryChange is one summary row the rebuild creates, corrects or removes
+type SummaryChange struct {
+	Action        string      `json:"action" example:"update"`
+	UserSessionID uuid.UUID   `json:"user_session_id"`
+	CategoryID    uuid.UUID   `json:"category_id"`
+	Category      string      `json:"category"`
+	PeriodType    string      `json:"period_type"`
+	PeriodStart   time.Time   `json:"period_start"`
+	Before        model.Money `json:"before"`
+	After         model.Money `json:"after"`
+}
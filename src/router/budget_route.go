@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func BudgetRoutes(v1 fiber.Router, b service.BudgetService, u service.UserService) {
+	budgetController := controller.NewBudgetController(b)
+
+	budget := v1.Group("/budgets", m.Auth(u))
+
+	budget.Get("/", budgetController.GetBudgets)
+	budget.Post("/", budgetController.CreateBudget)
+	budget.Get("/status", budgetController.GetBudgetStatus)
+	budget.Patch("/:budgetId", budgetController.UpdateBudget)
+	budget.Delete("/:budgetId", budgetController.DeleteBudget)
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CategoryAliasRoutes(v1 fiber.Router, cas service.CategoryAliasService, u service.UserService) {
+	categoryAliasController := controller.NewCategoryAliasController(cas)
+
+	alias := v1.Group("/category-aliases")
+
+	alias.Get("/", m.Auth(u, "manageCategories"), categoryAliasController.GetCategoryAliases)
+	alias.Post("/", m.Auth(u, "manageCategories"), categoryAliasController.CreateCategoryAlias)
+	alias.Delete("/:aliasId", m.Auth(u, "manageCategories"), categoryAliasController.DeleteCategoryAlias)
+
+	review := v1.Group("/category-reviews")
+
+	review.Get("/", m.Auth(u, "manageCategories"), categoryAliasController.GetCategoryLabelReviews)
+	review.Post("/:reviewId/resolve", m.Auth(u, "manageCategories"), categoryAliasController.ResolveCategoryLabelReview)
+	review.Delete("/:reviewId", m.Auth(u, "manageCategories"), categoryAliasController.DismissCategoryLabelReview)
+}
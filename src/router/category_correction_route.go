@@ -0,0 +1,19 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CategoryCorrectionRoutes are open to the user themselves and to admins
+func CategoryCorrectionRoutes(v1 fiber.Router, cc service.CategoryCorrectionService, u service.UserService) {
+	categoryCorrectionController := controller.NewCategoryCorrectionController(cc)
+
+	user := v1.Group("/users/:userId/category-corrections")
+
+	user.Get("/", m.Auth(u, "manageCategories"), categoryCorrectionController.GetCategoryCorrections)
+	user.Get("/stats", m.Auth(u, "manageCategories"), categoryCorrectionController.GetCategoryCorrectionStats)
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CategoryRoutes(v1 fiber.Router, ca service.CategoryService, u service.UserService) {
+	categoryController := controller.NewCategoryController(ca)
+
+	category := v1.Group("/categories")
+
+	category.Get("/", m.Auth(u, "manageCategories"), categoryController.GetCategories)
+	category.Post("/", m.Auth(u, "manageCategories"), categoryController.CreateCategory)
+	category.Get("/tree", m.Auth(u, "manageCategories"), categoryController.GetCategoryTree)
+	category.Get("/:categoryId", m.Auth(u, "manageCategories"), categoryController.GetCategoryByID)
+	category.Patch("/:categoryId", m.Auth(u, "manageCategories"), categoryController.UpdateCategory)
+	category.Delete("/:categoryId", m.Auth(u, "manageCategories"), categoryController.DeleteCategory)
+	category.Post("/:categoryId/deactivate", m.Auth(u, "manageCategories"), categoryController.DeactivateCategory)
+	category.Post("/:categoryId/activate", m.Auth(u, "manageCategories"), categoryController.ActivateCategory)
+	category.Post("/:categoryId/merge", m.Auth(u, "manageCategories"), categoryController.MergeCategory)
+}
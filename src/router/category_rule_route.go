@@ -0,0 +1,22 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CategoryRuleRoutes(v1 fiber.Router, cr service.CategoryRuleService, u service.UserService) {
+	categoryRuleController := controller.NewCategoryRuleController(cr)
+
+	rule := v1.Group("/category-rules", m.Auth(u))
+
+	rule.Get("/", categoryRuleController.GetCategoryRules)
+	rule.Post("/", categoryRuleController.CreateCategoryRule)
+	rule.Patch("/:ruleId", categoryRuleController.UpdateCategoryRule)
+	rule.Delete("/:ruleId", categoryRuleController.DeleteCategoryRule)
+	rule.Get("/:ruleId/preview", categoryRuleController.PreviewCategoryRule)
+	rule.Post("/:ruleId/apply", categoryRuleController.ApplyCategoryRule)
+}
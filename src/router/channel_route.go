@@ -0,0 +1,28 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChannelRoutes are called by the chat channel (n8n) on behalf of chat sessions that no
+// user may have claimed yet, so they take a channel account instead of the user's token
+func ChannelRoutes(
+	r fiber.Router, spendingService *service.SpendingService, u service.UserService, se service.SessionService,
+	a service.AttachmentService, ex service.ExpenseExtractor,
+) {
+	spendingController := controller.NewSpendingController(*spendingService, se, a, ex)
+	channel := r.Group("/channel", m.Auth(u, "manageChannelSessions"))
+
+	channel.Post("/spendings", func(c *fiber.Ctx) error {
+		return spendingController.CreateChannelSpending(c)
+	})
+
+	// The channel sends the code into the session's thread
+	channel.Post("/sessions/claim-codes", func(c *fiber.Ctx) error {
+		return spendingController.CreateSessionClaimCode(c)
+	})
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ExchangeRateRoutes(v1 fiber.Router, e service.ExchangeRateService, u service.UserService) {
+	exchangeRateController := controller.NewExchangeRateController(e)
+
+	exchangeRate := v1.Group("/exchange-rates")
+
+	exchangeRate.Get("/", m.Auth(u), exchangeRateController.GetExchangeRates)
+	exchangeRate.Post("/", m.Auth(u, "manageExchangeRates"), exchangeRateController.CreateExchangeRate)
+	exchangeRate.Post("/import", m.Auth(u, "manageExchangeRates"), exchangeRateController.ImportExchangeRates)
+	exchangeRate.Delete("/:rateId", m.Auth(u, "manageExchangeRates"), exchangeRateController.DeleteExchangeRate)
+}
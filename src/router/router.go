@@ -3,28 +3,52 @@ package router
 import (
 	"app/src/config"
 	"app/src/service"
+	"app/src/utils"
 	"app/src/validation"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func Routes(app *fiber.App, db *gorm.DB) {
+func Routes(app *fiber.App, db *gorm.DB, summaryWorker service.SummaryWorker) {
 	validate := validation.Validator()
 
 	healthCheckService := service.NewHealthCheckService(db)
 	emailService := service.NewEmailService()
+	summaryService := service.NewSummaryService(db, validate)
 	userService := service.NewUserService(db, validate)
 	tokenService := service.NewTokenService(db, validate, userService)
 	authService := service.NewAuthService(db, validate, userService, tokenService)
-	spendingService := service.NewSpendingService(db, validate)
+	sessionService := service.NewSessionService(db, validate)
+	exchangeRateService := service.NewExchangeRateService(db, validate)
+	categoryService := service.NewCategoryService(db, validate)
+	categoryRuleService := service.NewCategoryRuleService(db, validate)
+	categoryAliasService := service.NewCategoryAliasService(db, validate)
+	categoryCorrectionService := service.NewCategoryCorrectionService(db, validate)
+	budgetService := service.NewBudgetService(db, validate)
+	fileStorage := service.NewLocalStorage(config.AttachmentDir)
+	spendingService := service.NewSpendingService(db, validate, sessionService, fileStorage, summaryWorker)
+	attachmentService := service.NewAttachmentService(db, fileStorage, spendingService)
+
+	expenseExtractor, err := service.NewExpenseExtractor(config.ExpenseExtractor)
+	if err != nil {
+		utils.Log.Fatalf("Invalid expense extractor: %+v", err)
+	}
 
 	v1 := app.Group("/v1")
 
 	HealthCheckRoutes(v1, healthCheckService)
 	AuthRoutes(v1, authService, userService, tokenService, emailService)
 	UserRoutes(v1, userService, tokenService)
-	SpendingRoutes(v1, &spendingService)
+	SpendingRoutes(v1, &spendingService, userService, sessionService, attachmentService, expenseExtractor)
+	ChannelRoutes(v1, &spendingService, userService, sessionService, attachmentService, expenseExtractor)
+	SummaryRoutes(v1, summaryService, userService)
+	ExchangeRateRoutes(v1, exchangeRateService, userService)
+	CategoryRoutes(v1, categoryService, userService)
+	CategoryRuleRoutes(v1, categoryRuleService, userService)
+	CategoryAliasRoutes(v1, categoryAliasService, userService)
+	CategoryCorrectionRoutes(v1, categoryCorrectionService, userService)
+	BudgetRoutes(v1, budgetService, userService)
 	// TODO: add another routes here...
 
 	if !config.IsProd {
@@ -2,14 +2,18 @@ package router
 
 import (
 	"app/src/controller"
+	m "app/src/middleware"
 	"app/src/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SpendingRoutes(r fiber.Router, spendingService *service.SpendingService) {
-	spendingController := controller.NewSpendingController(*spendingService)
-	spending := r.Group("/spending")
+func SpendingRoutes(
+	r fiber.Router, spendingService *service.SpendingService, u service.UserService, se service.SessionService,
+	a service.AttachmentService, ex service.ExpenseExtractor,
+) {
+	spendingController := controller.NewSpendingController(*spendingService, se, a, ex)
+	spending := r.Group("/spending", m.Auth(u))
 
 	spending.Post("/", func(c *fiber.Ctx) error {
 		return spendingController.CreateSpending(c)
@@ -19,10 +23,18 @@ func SpendingRoutes(r fiber.Router, spendingService *service.SpendingService) {
 		return spendingController.GetSpending(c)
 	})
 
+	spending.Get("/pending", func(c *fiber.Ctx) error {
+		return spendingController.GetPendingSpendings(c)
+	})
+
 	spending.Get("/categories", func(c *fiber.Ctx) error {
 		return spendingController.GetCategories(c)
 	})
 
+	spending.Get("/categories/tree", func(c *fiber.Ctx) error {
+		return spendingController.GetCategoryTree(c)
+	})
+
 	spending.Get("/summary", func(c *fiber.Ctx) error {
 		return spendingController.GetSummarySpending(c)
 	})
@@ -30,4 +42,48 @@ func SpendingRoutes(r fiber.Router, spendingService *service.SpendingService) {
 	spending.Get("/summary/total", func(c *fiber.Ctx) error {
 		return spendingController.GetSummaryTotal(c)
 	})
+
+	spending.Get("/timeseries", func(c *fiber.Ctx) error {
+		return spendingController.GetTimeseries(c)
+	})
+
+	spending.Get("/comparison", func(c *fiber.Ctx) error {
+		return spendingController.GetComparison(c)
+	})
+
+	spending.Get("/sessions", func(c *fiber.Ctx) error {
+		return spendingController.GetSessions(c)
+	})
+
+	spending.Post("/sessions", func(c *fiber.Ctx) error {
+		return spendingController.ClaimSession(c)
+	})
+
+	spending.Get("/:spendingId", func(c *fiber.Ctx) error {
+		return spendingController.GetSpendingByID(c)
+	})
+
+	spending.Patch("/:spendingId", func(c *fiber.Ctx) error {
+		return spendingController.UpdateSpending(c)
+	})
+
+	spending.Delete("/:spendingId", func(c *fiber.Ctx) error {
+		return spendingController.DeleteSpending(c)
+	})
+
+	spending.Post("/:spendingId/confirm", func(c *fiber.Ctx) error {
+		return spendingController.ConfirmSpending(c)
+	})
+
+	spending.Post("/:spendingId/reject", func(c *fiber.Ctx) error {
+		return spendingController.RejectSpending(c)
+	})
+
+	spending.Get("/:spendingId/attachments", func(c *fiber.Ctx) error {
+		return spendingController.GetAttachments(c)
+	})
+
+	spending.Get("/:spendingId/attachments/:attachmentId", func(c *fiber.Ctx) error {
+		return spendingController.DownloadAttachment(c)
+	})
 }
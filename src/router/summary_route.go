@@ -0,0 +1,17 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SummaryRoutes(v1 fiber.Router, s service.SummaryService, u service.UserService) {
+	summaryController := controller.NewSummaryController(s)
+
+	summary := v1.Group("/summaries")
+
+	summary.Post("/rebuild", m.Auth(u, "manageSpendings"), summaryController.RebuildSummaries)
+}
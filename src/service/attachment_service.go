@@ -0,0 +1,177 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// allowedAttachmentTypes maps the sniffed content types we accept to a file extension
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type AttachmentService interface {
+	CheckFile(file *ExpenseFile) error
+	CreateAttachment(c *fiber.Ctx, spending *model.Spending, file *ExpenseFile) (*model.Attachment, error)
+	CreateReceiptAttachment(c *fiber.Ctx, receipt *model.Receipt, file *ExpenseFile) (*model.Attachment, error)
+	GetAttachments(c *fiber.Ctx, spendingID, userID string) ([]model.Attachment, error)
+	OpenAttachment(c *fiber.Ctx, spendingID, attachmentID, userID string) (*model.Attachment, io.ReadCloser, error)
+}
+
+type attachmentService struct {
+	Log             *logrus.Logger
+	DB              *gorm.DB
+	Storage         FileStorage
+	SpendingService SpendingService
+}
+
+func NewAttachmentService(db *gorm.DB, storage FileStorage, spendingService SpendingService) AttachmentService {
+	return &attachmentService{
+		Log:             utils.Log,
+		DB:              db,
+		Storage:         storage,
+		SpendingService: spendingService,
+	}
+}
+
+// CheckFile enforces the size limit and replaces the client supplied content
+// type with the one sniffed from the file content
+func (s *attachmentService) CheckFile(file *ExpenseFile) error {
+	if int64(len(file.Content)) > config.AttachmentMaxSize {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge,
+			fmt.Sprintf("File must not be larger than %d bytes", config.AttachmentMaxSize))
+	}
+
+	contentType := http.DetectContentType(file.Content)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	if _, allowed := allowedAttachmentTypes[contentType]; !allowed {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "Only JPEG, PNG, GIF, WebP and PDF files are allowed")
+	}
+
+	file.ContentType = contentType
+	return nil
+}
+
+func (s *attachmentService) CreateAttachment(c *fiber.Ctx, spending *model.Spending, file *ExpenseFile) (*model.Attachment, error) {
+	return s.createAttachment(c, spending.ID, &model.Attachment{SpendingID: &spending.ID}, file)
+}
+
+// CreateReceiptAttachment stores the file once for the receipt, every spending
+// of the receipt lists it among its attachments
+func (s *attachmentService) CreateReceiptAttachment(c *fiber.Ctx, receipt *model.Receipt, file *ExpenseFile) (*model.Attachment, error) {
+	return s.createAttachment(c, receipt.ID, &model.Attachment{ReceiptID: &receipt.ID}, file)
+}
+
+// createAttachment stores file under the folder of its owner and records it as attachment
+func (s *attachmentService) createAttachment(
+	c *fiber.Ctx, ownerID uuid.UUID, attachment *model.Attachment, file *ExpenseFile,
+) (*model.Attachment, error) {
+	if err := s.CheckFile(file); err != nil {
+		return nil, err
+	}
+
+	attachmentID := uuid.New()
+	key := fmt.Sprintf("%s/%s%s", ownerID, attachmentID, allowedAttachmentTypes[file.ContentType])
+
+	if err := s.Storage.Save(key, file.Content); err != nil {
+		s.Log.Errorf("Failed to store attachment: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to store attachment")
+	}
+
+	attachment.Filename = filepath.Base(file.Filename)
+	attachment.ContentType = file.ContentType
+	attachment.Size = int64(len(file.Content))
+	attachment.StorageKey = key
+
+	if err := s.DB.WithContext(c.Context()).Create(attachment).Error; err != nil {
+		s.Log.Errorf("Failed to create attachment: %+v", err)
+		if errDelete := s.Storage.Delete(key); errDelete != nil {
+			s.Log.Errorf("Failed to remove orphaned attachment file: %+v", errDelete)
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentService) GetAttachments(c *fiber.Ctx, spendingID, userID string) ([]model.Attachment, error) {
+	// Resolve the spending first so attachments of other users stay hidden
+	spending, err := s.SpendingService.GetSpendingByID(c, spendingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []model.Attachment
+
+	result := s.DB.WithContext(c.Context()).
+		Scopes(attachmentsOf(spending)).
+		Order("created_at asc").
+		Find(&attachments)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get attachments: %+v", result.Error)
+	}
+
+	return attachments, result.Error
+}
+
+func (s *attachmentService) OpenAttachment(
+	c *fiber.Ctx, spendingID, attachmentID, userID string,
+) (*model.Attachment, io.ReadCloser, error) {
+	spending, err := s.SpendingService.GetSpendingByID(c, spendingID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment := new(model.Attachment)
+
+	result := s.DB.WithContext(c.Context()).
+		Scopes(attachmentsOf(spending)).
+		First(attachment, "id = ?", attachmentID)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "Attachment not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed get attachment by id: %+v", result.Error)
+		return nil, nil, result.Error
+	}
+
+	file, err := s.Storage.Open(attachment.StorageKey)
+	if err != nil {
+		s.Log.Errorf("Failed to open attachment file: %+v", err)
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "Attachment file not found")
+	}
+
+	return attachment, file, nil
+}
+
+// attachmentsOf filters the attachments of a spending, including the ones of its receipt
+func attachmentsOf(spending *model.Spending) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if spending.ReceiptID == nil {
+			return db.Where("spending_id = ?", spending.ID)
+		}
+		return db.Where("(spending_id = ? OR receipt_id = ?)", spending.ID, *spending.ReceiptID)
+	}
+}
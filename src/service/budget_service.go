@@ -0,0 +1,327 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetService interface {
+	GetBudgets(c *fiber.Ctx, userID string) ([]model.Budget, error)
+	CreateBudget(c *fiber.Ctx, req *validation.CreateBudget, userID string) (*model.Budget, error)
+	UpdateBudget(c *fiber.Ctx, req *validation.UpdateBudget, id, userID string) (*model.Budget, error)
+	DeleteBudget(c *fiber.Ctx, id, userID string) error
+	GetBudgetStatus(c *fiber.Ctx, userID string) ([]response.BudgetStatus, error)
+}
+
+type budgetService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewBudgetService(db *gorm.DB, validate *validator.Validate) BudgetService {
+	return &budgetService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetBudgets returns the user's budgets, monthly ones first
+func (s *budgetService) GetBudgets(c *fiber.Ctx, userID string) ([]model.Budget, error) {
+	var budgets []model.Budget
+
+	result := s.DB.WithContext(c.Context()).
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Order("period asc, created_at asc").
+		Find(&budgets)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get budgets: %+v", result.Error)
+	}
+
+	return budgets, result.Error
+}
+
+// CreateBudget adds a budget in the given currency, the user's base currency by default
+func (s *budgetService) CreateBudget(
+	c *fiber.Ctx, req *validation.CreateBudget, userID string,
+) (*model.Budget, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	budget := &model.Budget{UserID: userUUID, Period: req.Period}
+
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		owner, err := ownerSessionIDs(tx, userUUID)
+		if err != nil {
+			return err
+		}
+
+		if err := setBudgetCategory(tx, budget, owner, req.Category); err != nil {
+			return err
+		}
+
+		prefs, err := loadSessionPreferences(tx, owner[0])
+		if err != nil {
+			return err
+		}
+
+		if budget.Amount, err = parseAmount(req.Amount, currencyOr(req.Currency, prefs.BaseCurrency)); err != nil {
+			return err
+		}
+
+		return tx.Omit("Category").Create(budget).Error
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "A "+req.Period+" budget for this category already exists")
+	}
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create budget: %+v", err)
+		}
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) UpdateBudget(
+	c *fiber.Ctx, req *validation.UpdateBudget, id, userID string,
+) (*model.Budget, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	budget := new(model.Budget)
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := findBudget(tx.Clauses(clause.Locking{Strength: "UPDATE"}), budget, id, userID); err != nil {
+			return err
+		}
+
+		if req.Category != "" {
+			owner, err := ownerSessionIDs(tx, budget.UserID)
+			if err != nil {
+				return err
+			}
+			if err := setBudgetCategory(tx, budget, owner, req.Category); err != nil {
+				return err
+			}
+		}
+		if req.Period != nil {
+			budget.Period = *req.Period
+		}
+
+		if req.Amount != nil || req.Currency != nil {
+			amount := json.Number(budget.Amount.String())
+			if req.Amount != nil {
+				amount = *req.Amount
+			}
+			currency := budget.Amount.Currency
+			if req.Currency != nil {
+				currency = *req.Currency
+			}
+
+			var err error
+			if budget.Amount, err = parseAmount(amount, currency); err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit("Category").Save(budget).Error
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "A "+budget.Period+" budget for this category already exists")
+	}
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to update budget: %+v", err)
+		}
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) DeleteBudget(c *fiber.Ctx, id, userID string) error {
+	result := s.DB.WithContext(c.Context()).Delete(&model.Budget{}, "id = ? AND user_id = ?", id, userID)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to delete budget: %+v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Budget not found")
+	}
+
+	return nil
+}
+
+// GetBudgetStatus reports every budget of the user against the current week or month.
+// Spending is read from the summaries of the budget's category and its subcategories,
+// so the period and the amounts follow the user's timezone and base currency. Budgets
+// in another currency are converted at today's rate.
+func (s *budgetService) GetBudgetStatus(c *fiber.Ctx, userID string) ([]response.BudgetStatus, error) {
+	budgets, err := s.GetBudgets(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]response.BudgetStatus, 0, len(budgets))
+	if len(budgets) == 0 {
+		return statuses, nil
+	}
+
+	db := s.DB.WithContext(c.Context())
+	owner, err := ownerSessionIDs(db, budgets[0].UserID)
+	if err != nil {
+		s.Log.Errorf("Failed to get user session ids: %+v", err)
+		return nil, err
+	}
+
+	prefs, err := loadSessionPreferences(db, owner[0])
+	if err != nil {
+		s.Log.Errorf("Failed to load preferences: %+v", err)
+		return nil, err
+	}
+
+	now := time.Now().In(prefs.Location)
+
+	err = withOwner(db, owner, func(tx *gorm.DB) error {
+		for _, budget := range budgets {
+			start, end := summaryPeriodRange(budget.Period)(now)
+
+			limit, _, err := prefs.toBase(tx, budget.Amount, now)
+			if err != nil {
+				return err
+			}
+
+			var spent int64
+			if err := tx.Raw(`
+				SELECT COALESCE(SUM(total_amount_minor), 0) FROM category_spending_summaries
+				WHERE user_session_id IN @owner AND period_type = @period AND period_start = @start
+					AND category_id IN (SELECT id FROM (`+categorySubtreeSQL+`) AS subtree)`,
+				map[string]interface{}{
+					"owner":    owner,
+					"period":   budget.Period,
+					"start":    start,
+					"category": budget.CategoryID,
+				},
+			).Scan(&spent).Error; err != nil {
+				return err
+			}
+
+			projected := ProjectSpending(spent, start, end.Add(time.Nanosecond), now)
+			currency := prefs.BaseCurrency
+
+			var percentUsed float64
+			if limit.Minor > 0 {
+				percentUsed = math.Round(float64(spent)/float64(limit.Minor)*10000) / 100
+			}
+
+			statuses = append(statuses, response.BudgetStatus{
+				Budget:             budget,
+				PeriodStart:        start,
+				PeriodEnd:          end,
+				Limit:              limit,
+				Spent:              model.NewMoney(spent, currency),
+				Remaining:          model.NewMoney(limit.Minor-spent, currency),
+				PercentUsed:        percentUsed,
+				Projected:          model.NewMoney(projected, currency),
+				ProjectedOverspend: model.NewMoney(max(projected-limit.Minor, 0), currency),
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to get budget status: %+v", err)
+		}
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// minProjectionElapsed keeps the first moments of a period from projecting a single
+// spending into a huge total
+const minProjectionElapsed = 24 * time.Hour
+
+// ProjectSpending extends what was spent between start and now linearly to end, counting
+// at least a day as elapsed. Once the period ended it is what was spent.
+func ProjectSpending(spent int64, start, end, now time.Time) int64 {
+	if !now.Before(end) {
+		return spent
+	}
+
+	elapsed := max(now.Sub(start), minProjectionElapsed)
+	if elapsed >= end.Sub(start) {
+		return spent
+	}
+
+	return int64(math.Round(float64(spent) * float64(end.Sub(start)) / float64(elapsed)))
+}
+
+// findBudget loads a budget of the user with its category
+func findBudget(tx *gorm.DB, budget *model.Budget, id, userID string) error {
+	result := tx.Preload("Category").First(budget, "id = ? AND user_id = ?", id, userID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Budget not found")
+	}
+	return result.Error
+}
+
+// setBudgetCategory points a budget at the category a name means to its owner, their own
+// category else the default one. A budget never creates a category, so a typo fails.
+func setBudgetCategory(tx *gorm.DB, budget *model.Budget, owner []uuid.UUID, name string) error {
+	var categories []model.Category
+	if err := tx.Scopes(categoriesVisibleTo(owner)).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("owner_id IS NULL, created_at").
+		Limit(1).
+		Find(&categories).Error; err != nil {
+		return err
+	}
+
+	if len(categories) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Category "+name+" not found")
+	}
+
+	if !categories[0].IsActive {
+		return fiber.NewError(fiber.StatusBadRequest, "Category "+categories[0].Name+" is deactivated")
+	}
+
+	budget.CategoryID = categories[0].ID
+	budget.Category = &categories[0]
+	return nil
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryAliasService interface {
+	GetCategoryAliases(c *fiber.Ctx, params *validation.QueryCategoryAlias) ([]model.CategoryAlias, int64, error)
+	CreateCategoryAlias(c *fiber.Ctx, req *validation.CreateCategoryAlias) (*response.CategoryAlias, error)
+	DeleteCategoryAlias(c *fiber.Ctx, id string) error
+	GetCategoryLabelReviews(
+		c *fiber.Ctx, params *validation.QueryCategoryLabelReview,
+	) ([]model.CategoryLabelReview, int64, error)
+	ResolveCategoryLabelReview(
+		c *fiber.Ctx, req *validation.ResolveCategoryLabelReview, id string,
+	) (*response.CategoryAlias, error)
+	DismissCategoryLabelReview(c *fiber.Ctx, id string) error
+}
+
+type categoryAliasService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCategoryAliasService(db *gorm.DB, validate *validator.Validate) CategoryAliasService {
+	return &categoryAliasService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *categoryAliasService) GetCategoryAliases(
+	c *fiber.Ctx, params *validation.QueryCategoryAlias,
+) ([]model.CategoryAlias, int64, error) {
+	var aliases []model.CategoryAlias
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Model(&model.CategoryAlias{}).Order("normalized_label asc")
+
+	if search := params.Search; search != "" {
+		query = query.Where("label ILIKE ? OR normalized_label LIKE ?",
+			"%"+search+"%", "%"+model.NormalizeCategoryLabel(search)+"%")
+	}
+
+	if categoryID := params.CategoryID; categoryID != "" {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count category aliases: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	result = query.Preload("Category").Limit(params.Limit).Offset(offset).Find(&aliases)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get category aliases: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	return aliases, totalResults, nil
+}
+
+// CreateCategoryAlias maps a label to a default category. Spendings filed under Other
+// while the label waited for review move into that category.
+func (s *categoryAliasService) CreateCategoryAlias(
+	c *fiber.Ctx, req *validation.CreateCategoryAlias,
+) (*response.CategoryAlias, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	var alias *response.CategoryAlias
+
+	err := withoutOwner(s.DB.WithContext(c.Context()), func(tx *gorm.DB) error {
+		var err error
+		alias, err = createCategoryAlias(tx, req.Label, req.CategoryID)
+		return err
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create category alias: %+v", err)
+		}
+		return nil, err
+	}
+
+	return alias, nil
+}
+
+// DeleteCategoryAlias removes an alias, spendings already filed through it stay where they are
+func (s *categoryAliasService) DeleteCategoryAlias(c *fiber.Ctx, id string) error {
+	result := s.DB.WithContext(c.Context()).Delete(&model.CategoryAlias{}, "id = ?", id)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to delete category alias: %+v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Category alias not found")
+	}
+
+	return nil
+}
+
+// GetCategoryLabelReviews returns the labels waiting for review, the most frequent first
+func (s *categoryAliasService) GetCategoryLabelReviews(
+	c *fiber.Ctx, params *validation.QueryCategoryLabelReview,
+) ([]model.CategoryLabelReview, int64, error) {
+	var reviews []model.CategoryLabelReview
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Model(&model.CategoryLabelReview{}).
+		Order("occurrences desc, last_seen_at desc")
+
+	if search := params.Search; search != "" {
+		query = query.Where("label ILIKE ? OR normalized_label LIKE ?",
+			"%"+search+"%", "%"+model.NormalizeCategoryLabel(search)+"%")
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count category label reviews: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	result = query.Limit(params.Limit).Offset(offset).Find(&reviews)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get category label reviews: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	return reviews, totalResults, nil
+}
+
+// ResolveCategoryLabelReview makes the reviewed label an alias of a default category,
+// see CreateCategoryAlias
+func (s *categoryAliasService) ResolveCategoryLabelReview(
+	c *fiber.Ctx, req *validation.ResolveCategoryLabelReview, id string,
+) (*response.CategoryAlias, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	var alias *response.CategoryAlias
+
+	err := withoutOwner(s.DB.WithContext(c.Context()), func(tx *gorm.DB) error {
+		review := new(model.CategoryLabelReview)
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(review, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Category label review not found")
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var err error
+		alias, err = createCategoryAlias(tx, review.Label, req.CategoryID)
+		return err
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to resolve category label review: %+v", err)
+		}
+		return nil, err
+	}
+
+	return alias, nil
+}
+
+// DismissCategoryLabelReview drops a label from the queue, its spendings stay in Other.
+// The label is queued again the next time the extractor gives it.
+func (s *categoryAliasService) DismissCategoryLabelReview(c *fiber.Ctx, id string) error {
+	result := s.DB.WithContext(c.Context()).Delete(&model.CategoryLabelReview{}, "id = ?", id)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to dismiss category label review: %+v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Category label review not found")
+	}
+
+	return nil
+}
+
+// createCategoryAlias maps label to the default category with id categoryID, removes
+// the label from the review queue and files its spendings waiting in Other
+func createCategoryAlias(tx *gorm.DB, label, categoryID string) (*response.CategoryAlias, error) {
+	normalized := model.NormalizeCategoryLabel(label)
+	if normalized == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Label must contain a letter or digit")
+	}
+
+	category := new(model.Category)
+	result := tx.First(category, "id = ?", categoryID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Category not found")
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if category.OwnerID != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Aliases can only point to default categories")
+	}
+	if !category.IsActive {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Category "+category.Name+" is deactivated")
+	}
+
+	var existing []model.CategoryAlias
+	if err := tx.Preload("Category").
+		Where("normalized_label = ?", normalized).
+		Limit(1).
+		Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fiber.NewError(fiber.StatusConflict,
+			"Label is already an alias of "+existing[0].Category.Name)
+	}
+
+	alias := &response.CategoryAlias{CategoryAlias: model.CategoryAlias{
+		Label:           label,
+		NormalizedLabel: normalized,
+		CategoryID:      category.ID,
+	}}
+	err := tx.Create(&alias.CategoryAlias).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Label is already an alias")
+	}
+	if err != nil {
+		return nil, err
+	}
+	alias.Category = category
+
+	if err := tx.Delete(&model.CategoryLabelReview{}, "normalized_label = ?", normalized).Error; err != nil {
+		return nil, err
+	}
+
+	alias.Recategorized, err = fileQueuedSpendings(tx, &alias.CategoryAlias)
+	return alias, err
+}
+
+// resolveCategoryLabel returns the category an extractor label means, comparing
+// normalized labels: a category of the owner, else a default category with that name,
+// else the category the label is an alias of. categories are the ones the owner sees,
+// their own first. Unknown labels are queued for review and resolve to Other.
+func resolveCategoryLabel(tx *gorm.DB, categories []model.Category, label string) (*model.Category, error) {
+	normalized := model.NormalizeCategoryLabel(label)
+
+	category, err := matchCategoryLabel(tx, categories, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == nil {
+		if normalized != "" && normalized != model.NormalizeCategoryLabel(defaultCategory) {
+			if err := queueCategoryLabel(tx, label, normalized); err != nil {
+				return nil, err
+			}
+		}
+		if category, err = otherCategory(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if !category.IsActive {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Category "+category.Name+" is deactivated")
+	}
+
+	return category, nil
+}
+
+func matchCategoryLabel(tx *gorm.DB, categories []model.Category, normalized string) (*model.Category, error) {
+	if normalized == "" {
+		return nil, nil
+	}
+
+	for i := range categories {
+		if model.NormalizeCategoryLabel(categories[i].Name) == normalized {
+			return &categories[i], nil
+		}
+	}
+
+	var aliases []model.CategoryAlias
+	if err := tx.Preload("Category").
+		Where("normalized_label = ?", normalized).
+		Limit(1).
+		Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+
+	return aliases[0].Category, nil
+}
+
+// queueCategoryLabel adds an unknown label to the review queue, or counts one more
+// occurrence when it is already waiting
+func queueCategoryLabel(tx *gorm.DB, label, normalized string) error {
+	review := &model.CategoryLabelReview{
+		Label:           label,
+		NormalizedLabel: normalized,
+		Occurrences:     1,
+		LastSeenAt:      time.Now(),
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "normalized_label"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"occurrences":  gorm.Expr("category_label_reviews.occurrences + 1"),
+			"last_seen_at": gorm.Expr("excluded.last_seen_at"),
+			"updated_at":   gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(review).Error
+}
+
+// otherCategory returns the default category spendings wait in while their label is
+// reviewed, creating it again when it was deleted
+func otherCategory(tx *gorm.DB) (*model.Category, error) {
+	category := new(model.Category)
+	err := tx.Where("owner_id IS NULL AND LOWER(name) = LOWER(?)", defaultCategory).
+		Attrs(model.Category{Name: defaultCategory, IsActive: true}).
+		FirstOrCreate(category).Error
+
+	return category, err
+}
+
+// fileQueuedSpendings moves the spendings waiting in Other whose extractor label is the
+// alias' label into the alias' category, moving their summaries as well
+func fileQueuedSpendings(tx *gorm.DB, alias *model.CategoryAlias) (int, error) {
+	var others []uuid.UUID
+	if err := tx.Model(&model.Category{}).
+		Where("owner_id IS NULL AND LOWER(name) = LOWER(?) AND id <> ?", defaultCategory, alias.CategoryID).
+		Pluck("id", &others).Error; err != nil {
+		return 0, err
+	}
+	if len(others) == 0 {
+		return 0, nil
+	}
+
+	var queued, batch []model.Spending
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("category_id IN ? AND category_label <> ''", others).
+		FindInBatches(&batch, 1000, func(_ *gorm.DB, _ int) error {
+			for _, spending := range batch {
+				if model.NormalizeCategoryLabel(spending.CategoryLabel) == alias.NormalizedLabel {
+					queued = append(queued, spending)
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range queued {
+		if err := recategorizeSpending(tx, &queued[i], alias.Category); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(queued), nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxCorrectionHistory is how many of a user's latest corrections suggestions look at
+const maxCorrectionHistory = 500
+
+// A suggestion needs minSuggestionVotes corrections of similar names, names sharing at
+// least suggestionSimilarity of their words, and suggestionAgreement of their weight
+const (
+	suggestionSimilarity = 0.5
+	minSuggestionVotes   = 2
+	suggestionAgreement  = 0.8
+)
+
+type CategoryCorrectionService interface {
+	GetCategoryCorrections(
+		c *fiber.Ctx, params *validation.QueryCategoryCorrection,
+	) ([]model.CategoryCorrection, int64, error)
+	GetCategoryCorrectionStats(c *fiber.Ctx, userID string) (*response.CategoryCorrectionStats, error)
+}
+
+type categoryCorrectionService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCategoryCorrectionService(db *gorm.DB, validate *validator.Validate) CategoryCorrectionService {
+	return &categoryCorrectionService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetCategoryCorrections returns the user's corrections, the latest first
+func (s *categoryCorrectionService) GetCategoryCorrections(
+	c *fiber.Ctx, params *validation.QueryCategoryCorrection,
+) ([]model.CategoryCorrection, int64, error) {
+	var corrections []model.CategoryCorrection
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Model(&model.CategoryCorrection{}).
+		Where("user_id = ?", params.UserID).
+		Order("updated_at desc")
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count category corrections: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	result = query.Preload("Category").Limit(params.Limit).Offset(offset).Find(&corrections)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get category corrections: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	return corrections, totalResults, nil
+}
+
+// GetCategoryCorrectionStats compares the user's extracted spendings with the ones they
+// corrected, per extractor label. Corrections of deleted spendings are left out.
+func (s *categoryCorrectionService) GetCategoryCorrectionStats(
+	c *fiber.Ctx, userID string,
+) (*response.CategoryCorrectionStats, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var extracted []struct {
+		Label string
+		Count int64
+	}
+	var corrected []struct {
+		Label      string
+		CategoryID uuid.UUID
+		Category   string
+		Count      int64
+	}
+
+	db := s.DB.WithContext(c.Context())
+	owner, err := ownerSessionIDs(db, userUUID)
+	if err != nil {
+		s.Log.Errorf("Failed to get user session ids: %+v", err)
+		return nil, err
+	}
+
+	err = withOwner(db, owner, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Spending{}).
+			Scopes(OwnedBy(owner...)).
+			Select("category_label AS label, COUNT(*) AS count").
+			Where("category_label <> ''").
+			Group("category_label").
+			Scan(&extracted).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("category_corrections").
+			Select("category_corrections.extractor_label AS label, categories.id AS category_id, "+
+				"categories.name AS category, COUNT(*) AS count").
+			Joins("JOIN categories ON categories.id = category_corrections.category_id").
+			Where("category_corrections.user_id = ? AND category_corrections.spending_id IS NOT NULL", userUUID).
+			Group("category_corrections.extractor_label, categories.id, categories.name").
+			Order("count desc, category asc").
+			Scan(&corrected).Error
+	})
+	if err != nil {
+		s.Log.Errorf("Failed to get category correction stats: %+v", err)
+		return nil, err
+	}
+
+	stats := &response.CategoryCorrectionStats{Labels: []response.CategoryLabelStats{}}
+	labels := make(map[string]*response.CategoryLabelStats)
+	label := func(name string) *response.CategoryLabelStats {
+		if labels[name] == nil {
+			labels[name] = &response.CategoryLabelStats{Label: name, CorrectedTo: []response.CorrectedCategory{}}
+		}
+		return labels[name]
+	}
+
+	for _, row := range extracted {
+		label(row.Label).Extracted += row.Count
+		stats.Extracted += row.Count
+	}
+	for _, row := range corrected {
+		labelStats := label(row.Label)
+		labelStats.Corrected += row.Count
+		labelStats.CorrectedTo = append(labelStats.CorrectedTo, response.CorrectedCategory{
+			CategoryID:  row.CategoryID,
+			Category:    row.Category,
+			Corrections: row.Count,
+		})
+	}
+
+	for _, labelStats := range labels {
+		stats.Corrected += labelStats.Corrected
+		stats.Labels = append(stats.Labels, *labelStats)
+	}
+
+	// The labels corrected the most come first, they are where the extractor is off
+	sort.Slice(stats.Labels, func(i, j int) bool {
+		a, b := stats.Labels[i], stats.Labels[j]
+		if a.Corrected != b.Corrected {
+			return a.Corrected > b.Corrected
+		}
+		if a.Extracted != b.Extracted {
+			return a.Extracted > b.Extracted
+		}
+		return a.Label < b.Label
+	})
+
+	if stats.Extracted > 0 {
+		stats.Accuracy = float64(stats.Extracted-stats.Corrected) / float64(stats.Extracted)
+	}
+
+	return stats, nil
+}
+
+// recordCategoryCorrection keeps the category a user moved an extracted spending into,
+// spending being the spending before the move. Moving it back into the category it was
+// created in drops the correction.
+func recordCategoryCorrection(tx *gorm.DB, userID uuid.UUID, spending *model.Spending, category *model.Category) error {
+	if spending.CategoryLabel == "" {
+		return nil
+	}
+
+	var corrections []model.CategoryCorrection
+	if err := tx.Where("spending_id = ?", spending.ID).Limit(1).Find(&corrections).Error; err != nil {
+		return err
+	}
+
+	if len(corrections) == 0 {
+		if categoryIDOf(spending) == category.ID {
+			return nil
+		}
+		return tx.Create(&model.CategoryCorrection{
+			UserID:             userID,
+			SpendingID:         &spending.ID,
+			RawText:            spending.Name,
+			ExtractorLabel:     spending.CategoryLabel,
+			OriginalCategoryID: spending.CategoryID,
+			CategoryID:         category.ID,
+		}).Error
+	}
+
+	correction := &corrections[0]
+	if correction.OriginalCategoryID != nil && *correction.OriginalCategoryID == category.ID {
+		return tx.Delete(correction).Error
+	}
+	return tx.Model(correction).Update("category_id", category.ID).Error
+}
+
+// SuggestCategory returns the category a user's corrections consistently moved spendings
+// named like name into, or nil. A correction counts when its name shares at least
+// suggestionSimilarity of the words of both names, weighted by that share. Corrections
+// into deactivated categories are left out.
+func SuggestCategory(corrections []model.CategoryCorrection, name string) *model.Category {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return nil
+	}
+
+	type vote struct {
+		category *model.Category
+		count    int
+		weight   float64
+	}
+	votes := make(map[uuid.UUID]*vote)
+	var best *vote
+	var total float64
+
+	for i := range corrections {
+		correction := &corrections[i]
+		if correction.Category == nil || !correction.Category.IsActive {
+			continue
+		}
+
+		similarity := wordSimilarity(words, nameWords(correction.RawText))
+		if similarity < suggestionSimilarity {
+			continue
+		}
+
+		v, found := votes[correction.CategoryID]
+		if !found {
+			v = &vote{category: correction.Category}
+			votes[correction.CategoryID] = v
+		}
+		v.count++
+		v.weight += similarity
+		total += similarity
+
+		if best == nil || v.weight > best.weight {
+			best = v
+		}
+	}
+
+	if best == nil || best.count < minSuggestionVotes || best.weight < suggestionAgreement*total {
+		return nil
+	}
+	return best.category
+}
+
+// nameWords returns the distinct words of a spending name, compared like category labels
+func nameWords(name string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, word := range strings.Fields(model.NormalizeCategoryLabel(name)) {
+		words[word] = struct{}{}
+	}
+	return words
+}
+
+// wordSimilarity is the Jaccard index of two word sets: the shared words over all words
+func wordSimilarity(a, b map[string]struct{}) float64 {
+	shared := 0
+	for word := range a {
+		if _, found := b[word]; found {
+			shared++
+		}
+	}
+
+	all := len(a) + len(b) - shared
+	if all == 0 {
+		return 0
+	}
+	return float64(shared) / float64(all)
+}
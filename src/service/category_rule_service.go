@@ -0,0 +1,546 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CategoryRuleService interface {
+	GetCategoryRules(c *fiber.Ctx, userID string) ([]model.CategoryRule, error)
+	CreateCategoryRule(c *fiber.Ctx, req *validation.CreateCategoryRule, userID string) (*model.CategoryRule, error)
+	UpdateCategoryRule(c *fiber.Ctx, req *validation.UpdateCategoryRule, id, userID string) (*model.CategoryRule, error)
+	DeleteCategoryRule(c *fiber.Ctx, id, userID string) error
+	PreviewCategoryRule(c *fiber.Ctx, id, userID string) ([]model.Spending, error)
+	ApplyCategoryRule(c *fiber.Ctx, id, userID string) (int, error)
+}
+
+type categoryRuleService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCategoryRuleService(db *gorm.DB, validate *validator.Validate) CategoryRuleService {
+	return &categoryRuleService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetCategoryRules returns the user's rules in the order they are tried
+func (s *categoryRuleService) GetCategoryRules(c *fiber.Ctx, userID string) ([]model.CategoryRule, error) {
+	var rules []model.CategoryRule
+
+	result := s.DB.WithContext(c.Context()).
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Order("priority asc, created_at asc").
+		Find(&rules)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get category rules: %+v", result.Error)
+	}
+
+	return rules, result.Error
+}
+
+func (s *categoryRuleService) CreateCategoryRule(
+	c *fiber.Ctx, req *validation.CreateCategoryRule, userID string,
+) (*model.CategoryRule, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	rule := &model.CategoryRule{UserID: userUUID, Priority: req.Priority, IsActive: true}
+	conditions := categoryRuleConditions{
+		Field:     req.Field,
+		MatchType: req.MatchType,
+		Pattern:   req.Pattern,
+		MinAmount: req.MinAmount,
+		MaxAmount: req.MaxAmount,
+		Currency:  req.Currency,
+	}
+
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := setRuleCategory(tx, rule, req.Category); err != nil {
+			return err
+		}
+		if err := conditions.apply(tx, rule); err != nil {
+			return err
+		}
+		return tx.Omit("Category").Create(rule).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create category rule: %+v", err)
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *categoryRuleService) UpdateCategoryRule(
+	c *fiber.Ctx, req *validation.UpdateCategoryRule, id, userID string,
+) (*model.CategoryRule, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	rule := new(model.CategoryRule)
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := findCategoryRule(tx.Clauses(clause.Locking{Strength: "UPDATE"}), rule, id, userID); err != nil {
+			return err
+		}
+
+		if req.Category != "" {
+			if err := setRuleCategory(tx, rule, req.Category); err != nil {
+				return err
+			}
+		}
+		if req.Priority != nil {
+			rule.Priority = *req.Priority
+		}
+		if req.IsActive != nil {
+			rule.IsActive = *req.IsActive
+		}
+
+		conditions := conditionsOf(rule)
+		conditions.update(req)
+		if err := conditions.apply(tx, rule); err != nil {
+			return err
+		}
+
+		return tx.Omit("Category").Save(rule).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to update category rule: %+v", err)
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *categoryRuleService) DeleteCategoryRule(c *fiber.Ctx, id, userID string) error {
+	result := s.DB.WithContext(c.Context()).Delete(&model.CategoryRule{}, "id = ? AND user_id = ?", id, userID)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to delete category rule: %+v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Category rule not found")
+	}
+
+	return nil
+}
+
+// PreviewCategoryRule returns the user's spendings the rule matches that are in another
+// category, newest first. Other rules are not taken into account.
+func (s *categoryRuleService) PreviewCategoryRule(c *fiber.Ctx, id, userID string) ([]model.Spending, error) {
+	var spendings []model.Spending
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		rule := new(model.CategoryRule)
+		if err := findCategoryRule(tx, rule, id, userID); err != nil {
+			return err
+		}
+
+		return withRuleOwner(tx, rule, func(tx *gorm.DB, owner []uuid.UUID) error {
+			var err error
+			spendings, err = recategorizedSpendings(tx, rule, owner, false)
+			return err
+		})
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to preview category rule: %+v", err)
+		}
+		return nil, err
+	}
+
+	return spendings, nil
+}
+
+// ApplyCategoryRule moves the spendings PreviewCategoryRule returns into the rule's
+// category, moving the confirmed ones' amounts between the summaries as well
+func (s *categoryRuleService) ApplyCategoryRule(c *fiber.Ctx, id, userID string) (int, error) {
+	var applied int
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		rule := new(model.CategoryRule)
+		if err := findCategoryRule(tx, rule, id, userID); err != nil {
+			return err
+		}
+
+		if !rule.Category.IsActive {
+			return fiber.NewError(fiber.StatusBadRequest, "Category "+rule.Category.Name+" is deactivated")
+		}
+
+		return withRuleOwner(tx, rule, func(tx *gorm.DB, owner []uuid.UUID) error {
+			spendings, err := recategorizedSpendings(tx, rule, owner, true)
+			if err != nil {
+				return err
+			}
+
+			for i := range spendings {
+				if err := recategorizeSpending(tx, &spendings[i], rule.Category); err != nil {
+					return err
+				}
+			}
+
+			applied = len(spendings)
+			return nil
+		})
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to apply category rule: %+v", err)
+		}
+		return 0, err
+	}
+
+	return applied, nil
+}
+
+// findCategoryRule loads a rule of the user with its category
+func findCategoryRule(tx *gorm.DB, rule *model.CategoryRule, id, userID string) error {
+	result := tx.Preload("Category").First(rule, "id = ? AND user_id = ?", id, userID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Category rule not found")
+	}
+	return result.Error
+}
+
+// withRuleOwner runs fn with the rows of the rule owner's sessions visible
+func withRuleOwner(tx *gorm.DB, rule *model.CategoryRule, fn func(tx *gorm.DB, owner []uuid.UUID) error) error {
+	owner, err := ownerSessionIDs(tx, rule.UserID)
+	if err != nil {
+		return err
+	}
+
+	return withOwner(tx, owner, func(tx *gorm.DB) error {
+		return fn(tx, owner)
+	})
+}
+
+// recategorizedSpendings returns the spendings of the rule's owner that it matches and
+// that are in another category, locking them when lock is set
+func recategorizedSpendings(tx *gorm.DB, rule *model.CategoryRule, owner []uuid.UUID, lock bool) ([]model.Spending, error) {
+	matcher, err := newCategoryRuleMatcher(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	query := tx.Scopes(OwnedBy(owner...))
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var matched, batch []model.Spending
+	err = query.FindInBatches(&batch, 1000, func(_ *gorm.DB, _ int) error {
+		for _, spending := range batch {
+			if categoryIDOf(&spending) != rule.CategoryID && matcher.matches(&spending) {
+				matched = append(matched, spending)
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Datetime.After(matched[j].Datetime) })
+	return matched, nil
+}
+
+// setRuleCategory points a rule at the category a name means to its owner, creating an
+// own category when there is none
+func setRuleCategory(tx *gorm.DB, rule *model.CategoryRule, name string) error {
+	owner, err := ownerSessionIDs(tx, rule.UserID)
+	if err != nil {
+		return err
+	}
+
+	category, err := findOrCreateCategory(tx, owner, name)
+	if err != nil {
+		return err
+	}
+
+	rule.CategoryID = category.ID
+	rule.Category = category
+	return nil
+}
+
+// categoryRuleConditions are the conditions of a rule as they are written in requests
+type categoryRuleConditions struct {
+	Field     string
+	MatchType string
+	Pattern   string
+	MinAmount json.Number
+	MaxAmount json.Number
+	Currency  string
+}
+
+func conditionsOf(rule *model.CategoryRule) categoryRuleConditions {
+	conditions := categoryRuleConditions{
+		Field:     rule.Field,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Currency:  rule.AmountCurrency,
+	}
+	if amount := rule.MinAmount(); amount != nil {
+		conditions.MinAmount = json.Number(amount.String())
+	}
+	if amount := rule.MaxAmount(); amount != nil {
+		conditions.MaxAmount = json.Number(amount.String())
+	}
+	return conditions
+}
+
+func (conditions *categoryRuleConditions) update(req *validation.UpdateCategoryRule) {
+	if req.Field != nil {
+		conditions.Field = *req.Field
+	}
+	if req.MatchType != nil {
+		conditions.MatchType = *req.MatchType
+	}
+	if req.Pattern != nil {
+		conditions.Pattern = *req.Pattern
+	}
+	if req.MinAmount != nil {
+		conditions.MinAmount = *req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		conditions.MaxAmount = *req.MaxAmount
+	}
+	if req.Currency != nil {
+		conditions.Currency = *req.Currency
+	}
+}
+
+// apply checks the conditions and stores them in the rule. A pattern is matched in the
+// name unless another field is given, amounts are in the owner's base currency unless
+// another currency is given.
+func (conditions categoryRuleConditions) apply(tx *gorm.DB, rule *model.CategoryRule) error {
+	if conditions.Pattern == "" && conditions.MinAmount == "" && conditions.MaxAmount == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "A rule needs a pattern, an amount range or both")
+	}
+
+	rule.Field, rule.MatchType, rule.Pattern = "", "", conditions.Pattern
+	if conditions.Pattern != "" {
+		rule.Field, rule.MatchType = conditions.Field, conditions.MatchType
+		if rule.Field == "" {
+			rule.Field = "name"
+		}
+		if rule.MatchType == "" {
+			rule.MatchType = "contains"
+		}
+	}
+
+	rule.MinAmountMinor, rule.MaxAmountMinor, rule.AmountCurrency = nil, nil, ""
+	if conditions.MinAmount != "" || conditions.MaxAmount != "" {
+		currency := conditions.Currency
+		if currency == "" {
+			prefs, err := loadSessionPreferences(tx, rule.UserID)
+			if err != nil {
+				return err
+			}
+			currency = prefs.BaseCurrency
+		}
+		rule.AmountCurrency = currency
+
+		for _, bound := range []struct {
+			Value json.Number
+			Minor **int64
+		}{{conditions.MinAmount, &rule.MinAmountMinor}, {conditions.MaxAmount, &rule.MaxAmountMinor}} {
+			if bound.Value == "" {
+				continue
+			}
+			amount, err := parseAmount(bound.Value, currency)
+			if err != nil {
+				return err
+			}
+			*bound.Minor = &amount.Minor
+		}
+
+		if rule.MinAmountMinor != nil && rule.MaxAmountMinor != nil && *rule.MinAmountMinor > *rule.MaxAmountMinor {
+			return fiber.NewError(fiber.StatusBadRequest, "min_amount must not be greater than max_amount")
+		}
+	}
+
+	_, err := newCategoryRuleMatcher(rule)
+	return err
+}
+
+// categoryRuleMatcher is a rule ready to be matched against spendings
+type categoryRuleMatcher struct {
+	rule    *model.CategoryRule
+	pattern *regexp.Regexp
+}
+
+// newCategoryRuleMatcher compiles a rule. Both contains and regex patterns ignore case.
+func newCategoryRuleMatcher(rule *model.CategoryRule) (*categoryRuleMatcher, error) {
+	matcher := &categoryRuleMatcher{rule: rule}
+	if rule.Pattern == "" {
+		return matcher, nil
+	}
+
+	expr := regexp.QuoteMeta(rule.Pattern)
+	if rule.MatchType == "regex" {
+		expr = rule.Pattern
+	}
+
+	pattern, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid pattern, "+err.Error())
+	}
+	matcher.pattern = pattern
+
+	return matcher, nil
+}
+
+func (m *categoryRuleMatcher) matches(spending *model.Spending) bool {
+	if m.pattern != nil && !m.pattern.MatchString(ruleFieldOf(spending, m.rule.Field)) {
+		return false
+	}
+
+	if m.rule.MinAmountMinor == nil && m.rule.MaxAmountMinor == nil {
+		return true
+	}
+
+	// A spending in another currency matches on its amount in the owner's base currency
+	amount := spending.Amount
+	if amount.Currency != m.rule.AmountCurrency {
+		amount = spending.BaseAmount
+	}
+	if amount.Currency != m.rule.AmountCurrency {
+		return false
+	}
+
+	return (m.rule.MinAmountMinor == nil || amount.Minor >= *m.rule.MinAmountMinor) &&
+		(m.rule.MaxAmountMinor == nil || amount.Minor <= *m.rule.MaxAmountMinor)
+}
+
+func ruleFieldOf(spending *model.Spending, field string) string {
+	switch field {
+	case "description":
+		return spending.Description
+	case "merchant":
+		return spending.Merchant
+	default:
+		return spending.Name
+	}
+}
+
+// spendingCategorizer picks the category of the new spendings of one owner
+type spendingCategorizer struct {
+	categories  []model.Category
+	rules       []*categoryRuleMatcher
+	corrections []model.CategoryCorrection
+}
+
+// newSpendingCategorizer loads the categories whoever owns a session sees, their active
+// rules and their latest corrections. Unclaimed sessions have no rules.
+func newSpendingCategorizer(tx *gorm.DB, userSessionID uuid.UUID) (*spendingCategorizer, error) {
+	owner, err := sessionOwnerIDs(tx, userSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []model.Category
+	if err := tx.Scopes(categoriesVisibleTo(owner)).
+		Order("owner_id IS NULL, created_at").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var rules []model.CategoryRule
+	if err := tx.Preload("Category").
+		Where("user_id = ? AND is_active = ?", owner[0], true).
+		Order("priority asc, created_at asc").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	categorizer := &spendingCategorizer{categories: categories}
+	if err := tx.Preload("Category").
+		Where("user_id = ?", owner[0]).
+		Order("updated_at desc").
+		Limit(maxCorrectionHistory).
+		Find(&categorizer.corrections).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		matcher, err := newCategoryRuleMatcher(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+		categorizer.rules = append(categorizer.rules, matcher)
+	}
+
+	return categorizer, nil
+}
+
+// categorize puts a spending into the category of the first rule it matches, else into
+// the category the owner kept correcting similar names to, else into the category the
+// extractor's label means, see resolveCategoryLabel. Rules whose category was
+// deactivated are skipped.
+func (sc *spendingCategorizer) categorize(tx *gorm.DB, spending *model.Spending, label string) error {
+	spending.CategoryLabel = label
+
+	for _, matcher := range sc.rules {
+		category := matcher.rule.Category
+		if category.IsActive && matcher.matches(spending) {
+			spending.Category = category.Name
+			spending.CategoryID = &category.ID
+			return nil
+		}
+	}
+
+	if category := SuggestCategory(sc.corrections, spending.Name); category != nil {
+		spending.Category = category.Name
+		spending.CategoryID = &category.ID
+		return nil
+	}
+
+	category, err := resolveCategoryLabel(tx, sc.categories, label)
+	if err != nil {
+		return err
+	}
+
+	spending.Category = category.Name
+	spending.CategoryID = &category.ID
+	return nil
+}
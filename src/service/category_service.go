@@ -0,0 +1,681 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCategoryDepth is how deep categories nest, Transport > Motor > Bensin is 3 levels
+const maxCategoryDepth = 3
+
+// categorySubtreeSQL selects the id and level of @category and of every category below
+// it, the category itself being level 1
+const categorySubtreeSQL = `
+	WITH RECURSIVE subtree AS (
+		SELECT id, 1 AS level FROM categories WHERE id = @category
+		UNION ALL
+		SELECT c.id, subtree.level + 1 FROM categories c
+		JOIN subtree ON c.parent_id = subtree.id
+		WHERE subtree.level < 10
+	)
+	SELECT id, level FROM subtree`
+
+type CategoryService interface {
+	GetCategories(c *fiber.Ctx, params *validation.QueryCategory) ([]model.Category, int64, error)
+	GetCategoryTree(c *fiber.Ctx, params *validation.QueryCategory) ([]response.CategoryNode, error)
+	GetCategoryByID(c *fiber.Ctx, id string) (*model.Category, error)
+	CreateCategory(c *fiber.Ctx, req *validation.CreateCategory) (*model.Category, error)
+	UpdateCategory(c *fiber.Ctx, req *validation.UpdateCategory, id string) (*model.Category, error)
+	DeleteCategory(c *fiber.Ctx, id string) error
+	MergeCategory(c *fiber.Ctx, req *validation.MergeCategory, id string) (*response.MergeCategory, error)
+}
+
+type categoryService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCategoryService(db *gorm.DB, validate *validator.Validate) CategoryService {
+	return &categoryService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *categoryService) GetCategories(c *fiber.Ctx, params *validation.QueryCategory) ([]model.Category, int64, error) {
+	var categories []model.Category
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.filteredCategories(c, params)
+
+	if search := params.Search; search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count categories: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	result = query.Limit(params.Limit).Offset(offset).Find(&categories)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get categories: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	return categories, totalResults, nil
+}
+
+// GetCategoryTree returns the categories matching the status and owner filters as a
+// tree. A category whose parent is filtered out is listed at the top level.
+func (s *categoryService) GetCategoryTree(
+	c *fiber.Ctx, params *validation.QueryCategory,
+) ([]response.CategoryNode, error) {
+	var categories []model.Category
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	if err := s.filteredCategories(c, params).Find(&categories).Error; err != nil {
+		s.Log.Errorf("Failed to get categories: %+v", err)
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func (s *categoryService) filteredCategories(c *fiber.Ctx, params *validation.QueryCategory) *gorm.DB {
+	query := s.DB.WithContext(c.Context()).Model(&model.Category{}).Order("name asc")
+
+	switch params.Status {
+	case "", "active":
+		query = query.Where("is_active = ?", true)
+	case "inactive":
+		query = query.Where("is_active = ?", false)
+	}
+
+	switch params.Owner {
+	case "":
+	case "default":
+		query = query.Where("owner_id IS NULL")
+	default:
+		query = query.Where("owner_id = ?", params.Owner)
+	}
+
+	return query
+}
+
+func (s *categoryService) GetCategoryByID(c *fiber.Ctx, id string) (*model.Category, error) {
+	category := new(model.Category)
+
+	result := s.DB.WithContext(c.Context()).First(category, "id = ?", id)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Category not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed get category by id: %+v", result.Error)
+	}
+
+	return category, result.Error
+}
+
+func (s *categoryService) CreateCategory(c *fiber.Ctx, req *validation.CreateCategory) (*model.Category, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	// Categories created here are defaults, users get their own by spending in them
+	category := &model.Category{Name: req.Name, IsActive: true}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryName(tx, req.Name, nil, uuid.Nil); err != nil {
+			return err
+		}
+
+		if req.ParentID != "" {
+			parent, err := lockCategoryParent(tx, req.ParentID)
+			if err != nil {
+				return err
+			}
+			if err := checkCategoryParent(tx, category, parent); err != nil {
+				return err
+			}
+			category.ParentID = &parent.ID
+		}
+
+		return tx.Create(category).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create category: %+v", err)
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// UpdateCategory renames, moves, deactivates or reactivates a category. The name is
+// copied into spendings and summaries, so a rename updates them too.
+func (s *categoryService) UpdateCategory(c *fiber.Ctx, req *validation.UpdateCategory, id string) (*model.Category, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" && req.IsActive == nil && req.ParentID == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	category := new(model.Category)
+
+	err := withoutOwner(s.DB.WithContext(c.Context()), func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(category, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if req.IsActive != nil && *req.IsActive != category.IsActive {
+			category.IsActive = *req.IsActive
+			if err := tx.Model(category).Update("is_active", category.IsActive).Error; err != nil {
+				return err
+			}
+		}
+
+		if req.ParentID != nil {
+			if err := moveCategory(tx, category, *req.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if req.Name == "" || req.Name == category.Name {
+			return nil
+		}
+
+		if err := checkCategoryName(tx, req.Name, category.OwnerID, category.ID); err != nil {
+			return err
+		}
+		category.Name = req.Name
+		return renameCategory(tx, category)
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to update category: %+v", err)
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// DeleteCategory removes a category no spending uses, used categories are merged instead
+func (s *categoryService) DeleteCategory(c *fiber.Ctx, id string) error {
+	err := withoutOwner(s.DB.WithContext(c.Context()), func(tx *gorm.DB) error {
+		var spendings int64
+		if err := tx.Model(&model.Spending{}).Where("category_id = ?", id).Count(&spendings).Error; err != nil {
+			return err
+		}
+		if spendings > 0 {
+			return fiber.NewError(fiber.StatusConflict,
+				"Category is used by spendings, merge it into another category instead")
+		}
+
+		var children int64
+		if err := tx.Model(&model.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return fiber.NewError(fiber.StatusConflict, "Category has subcategories, move or delete them first")
+		}
+
+		result := tx.Delete(&model.Category{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+
+		// Rows of spendings deleted earlier may still be around with a zero total
+		return tx.Delete(&model.CategorySpendingSummary{}, "category_id = ?", id).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to delete category: %+v", err)
+		}
+	}
+
+	return err
+}
+
+// MergeCategory moves every spending, subcategory, rule, alias, correction and budget of
+// a category into another one and deletes it. The spendings, their summary rows and the
+// summary updates still queued are moved in one transaction, rows of the same period are
+// added together.
+func (s *categoryService) MergeCategory(
+	c *fiber.Ctx, req *validation.MergeCategory, id string,
+) (*response.MergeCategory, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.IntoCategoryID == id {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "A category cannot be merged into itself")
+	}
+
+	report := new(response.MergeCategory)
+
+	err := withoutOwner(s.DB.WithContext(c.Context()), func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+
+		var categories []model.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []string{id, req.IntoCategoryID}).
+			Find(&categories).Error; err != nil {
+			return err
+		}
+
+		var source, target *model.Category
+		for i := range categories {
+			if categories[i].ID.String() == id {
+				source = &categories[i]
+			} else {
+				target = &categories[i]
+			}
+		}
+		if source == nil || target == nil {
+			return fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+
+		// Spendings must keep seeing their category, so only defaults are shared
+		if target.OwnerID != nil && (source.OwnerID == nil || *source.OwnerID != *target.OwnerID) {
+			return fiber.NewError(fiber.StatusBadRequest,
+				"A category can only be merged into a default category or one of the same owner")
+		}
+
+		if err := moveSubcategories(tx, source, target); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.CategoryRule{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.CategoryAlias{}).
+			Where("category_id = ?", source.ID).
+			Update("category_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		for _, column := range []string{"category_id", "original_category_id"} {
+			if err := tx.Model(&model.CategoryCorrection{}).
+				Where(column+" = ?", source.ID).
+				Update(column, target.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// A budget the target already has for that period stays, the source's goes with it
+		if err := tx.Exec(`
+			UPDATE budgets SET category_id = @target, updated_at = NOW()
+			WHERE category_id = @source AND NOT EXISTS (
+				SELECT 1 FROM budgets b
+				WHERE b.user_id = budgets.user_id AND b.category_id = @target AND b.period = budgets.period
+			)`,
+			map[string]interface{}{"source": source.ID, "target": target.ID},
+		).Error; err != nil {
+			return err
+		}
+
+		// Waits for a worker applying one of the events, later runs skip them until we commit
+		if err := retargetSummaryEvents(tx, source.ID, target); err != nil {
+			return err
+		}
+
+		if err := lockSummaries(tx); err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Spending{}).
+			Where("category_id = ?", source.ID).
+			Updates(map[string]interface{}{"category_id": target.ID, "category": target.Name})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Spendings = result.RowsAffected
+
+		result = tx.Exec(`
+			INSERT INTO category_spending_summaries (
+				user_session_id, category_id, category, total_amount_minor, total_amount_currency,
+				period_start, period_end, period_type, created_at, updated_at
+			)
+			SELECT user_session_id, @target, @name, total_amount_minor, total_amount_currency,
+				period_start, period_end, period_type, NOW(), NOW()
+			FROM category_spending_summaries
+			WHERE category_id = @source
+			ON CONFLICT (user_session_id, category_id, period_type, period_start) DO UPDATE SET
+				total_amount_minor = category_spending_summaries.total_amount_minor + excluded.total_amount_minor,
+				updated_at = excluded.updated_at`,
+			map[string]interface{}{"source": source.ID, "target": target.ID, "name": target.Name},
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Summaries = result.RowsAffected
+
+		if err := tx.Delete(&model.CategorySpendingSummary{}, "category_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+
+		report.Category = *target
+		return tx.Delete(source).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to merge category: %+v", err)
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// categoriesVisibleTo limits a query on categories to the defaults and the categories
+// of the given user sessions
+func categoriesVisibleTo(owner []uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(categories.owner_id IS NULL OR categories.owner_id IN ?)", owner)
+	}
+}
+
+// findOrCreateCategory returns the category a name means to an owner, ignoring case:
+// their own category, else the default one, else a new category owned by owner[0]
+func findOrCreateCategory(tx *gorm.DB, owner []uuid.UUID, name string) (*model.Category, error) {
+	var categories []model.Category
+	if err := tx.Scopes(categoriesVisibleTo(owner)).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("owner_id IS NULL, created_at").
+		Limit(1).
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		category := &model.Category{Name: name, OwnerID: &owner[0], IsActive: true}
+		return category, tx.Create(category).Error
+	}
+
+	if !categories[0].IsActive {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Category "+categories[0].Name+" is deactivated")
+	}
+
+	return &categories[0], nil
+}
+
+// lockCategoryTree keeps other transactions from moving categories until this one ends,
+// moves checked at the same time could otherwise close a cycle together
+func lockCategoryTree(tx *gorm.DB) error {
+	return tx.Exec("LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE").Error
+}
+
+// lockCategoryParent locks the category tree and returns the category with the given id
+// to place another category under
+func lockCategoryParent(tx *gorm.DB, parentID string) (*model.Category, error) {
+	if err := lockCategoryTree(tx); err != nil {
+		return nil, err
+	}
+
+	parent := new(model.Category)
+	result := tx.First(parent, "id = ?", parentID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Parent category not found")
+	}
+
+	return parent, result.Error
+}
+
+// moveCategory places a category under the category with id parentID, or at the top
+// level when parentID is empty
+func moveCategory(tx *gorm.DB, category *model.Category, parentID string) error {
+	if parentID == "" {
+		category.ParentID = nil
+		return tx.Model(category).Update("parent_id", nil).Error
+	}
+
+	parent, err := lockCategoryParent(tx, parentID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkCategoryParent(tx, category, parent); err != nil {
+		return err
+	}
+
+	category.ParentID = &parent.ID
+	return tx.Model(category).Update("parent_id", parent.ID).Error
+}
+
+// checkCategoryParent fails when a category cannot be placed under parent: the parent
+// is in another owner's scope, is the category or one of its subcategories, or the
+// category's subtree would end up deeper than maxCategoryDepth
+func checkCategoryParent(tx *gorm.DB, category, parent *model.Category) error {
+	if parent.OwnerID != nil && (category.OwnerID == nil || *category.OwnerID != *parent.OwnerID) {
+		return fiber.NewError(fiber.StatusBadRequest,
+			"A category can only be placed under a default category or one of the same owner")
+	}
+
+	subtree, err := categorySubtree(tx, category.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := subtree[parent.ID]; ok {
+		return fiber.NewError(fiber.StatusBadRequest, "A category cannot be placed under itself or its subcategories")
+	}
+
+	return checkCategoryDepth(tx, parent, subtreeHeight(subtree, 1))
+}
+
+// checkCategoryDepth fails when a subtree height levels high would be nested deeper than
+// maxCategoryDepth under parent
+func checkCategoryDepth(tx *gorm.DB, parent *model.Category, height int) error {
+	var depth int
+	if err := tx.Raw("SELECT depth FROM category_roots WHERE id = ?", parent.ID).Scan(&depth).Error; err != nil {
+		return err
+	}
+
+	if depth+height > maxCategoryDepth {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("Categories cannot be nested more than %d levels deep", maxCategoryDepth))
+	}
+
+	return nil
+}
+
+// categorySubtree returns the level of a category and of every category below it, the
+// category itself being level 1. It is empty for a category that is not stored yet.
+func categorySubtree(tx *gorm.DB, id uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ID    uuid.UUID
+		Level int
+	}
+	if err := tx.Raw(categorySubtreeSQL, map[string]interface{}{"category": id}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	subtree := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		subtree[row.ID] = row.Level
+	}
+
+	return subtree, nil
+}
+
+// subtreeHeight returns the number of levels of a subtree, at least min
+func subtreeHeight(subtree map[uuid.UUID]int, min int) int {
+	height := min
+	for _, level := range subtree {
+		if level > height {
+			height = level
+		}
+	}
+	return height
+}
+
+// moveSubcategories places the subcategories of source under target, which must not be
+// one of them
+func moveSubcategories(tx *gorm.DB, source, target *model.Category) error {
+	subtree, err := categorySubtree(tx, source.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := subtree[target.ID]; ok {
+		return fiber.NewError(fiber.StatusBadRequest, "A category cannot be merged into its own subcategory")
+	}
+
+	// The subcategories take the place of source, one level less than its subtree
+	if height := subtreeHeight(subtree, 1) - 1; height > 0 {
+		if err := checkCategoryDepth(tx, target, height); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&model.Category{}).
+		Where("parent_id = ?", source.ID).
+		Update("parent_id", target.ID).Error
+}
+
+// buildCategoryTree nests categories under their parents, sorted by name. Categories
+// whose parent is not among them are at the top level.
+func buildCategoryTree(categories []model.Category) []response.CategoryNode {
+	listed := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		listed[category.ID] = true
+	}
+
+	children := make(map[uuid.UUID][]model.Category)
+	var roots []model.Category
+	for _, category := range categories {
+		if category.ParentID != nil && listed[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var build func(categories []model.Category) []response.CategoryNode
+	build = func(categories []model.Category) []response.CategoryNode {
+		sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+
+		nodes := make([]response.CategoryNode, len(categories))
+		for i, category := range categories {
+			nodes[i] = response.CategoryNode{Category: category, Children: build(children[category.ID])}
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// checkCategoryName fails when another category in the same scope already has the name,
+// ignoring case. Defaults are checked against defaults, a user's categories against the
+// defaults and the categories of every session of the same owner.
+func checkCategoryName(tx *gorm.DB, name string, ownerID *uuid.UUID, exceptID uuid.UUID) error {
+	query := tx.Model(&model.Category{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID)
+	if ownerID == nil {
+		query = query.Where("owner_id IS NULL")
+	} else {
+		owner, err := sessionOwnerIDs(tx, *ownerID)
+		if err != nil {
+			return err
+		}
+		query = query.Scopes(categoriesVisibleTo(owner))
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Category "+name+" already exists, merge the categories instead")
+	}
+
+	return nil
+}
+
+// renameCategory stores a new category name and copies it to the spendings, summaries
+// and queued summary updates of the category
+func renameCategory(tx *gorm.DB, category *model.Category) error {
+	if err := tx.Model(category).Update("name", category.Name).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.Spending{}).
+		Where("category_id = ?", category.ID).
+		Update("category", category.Name).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.CategorySpendingSummary{}).
+		Where("category_id = ?", category.ID).
+		Update("category", category.Name).Error; err != nil {
+		return err
+	}
+
+	return retargetSummaryEvents(tx, category.ID, category)
+}
+
+// retargetSummaryEvents points the pending spending_created events of a category at another one
+func retargetSummaryEvents(tx *gorm.DB, categoryID uuid.UUID, target *model.Category) error {
+	return tx.Exec(`
+		UPDATE outbox_events
+		SET payload = payload || jsonb_build_object('category_id', @target::text, 'category', @name::text)
+		WHERE type = @type AND status = @status AND payload->>'category_id' = @source::text`,
+		map[string]interface{}{
+			"source": categoryID,
+			"target": target.ID,
+			"name":   target.Name,
+			"type":   EventSpendingCreated,
+			"status": OutboxPending,
+		},
+	).Error
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// exchangeRateColumns is the header of an exchange rate CSV import
+var exchangeRateColumns = []string{"from_currency", "to_currency", "rate", "effective_date"}
+
+type ExchangeRateService interface {
+	GetExchangeRates(c *fiber.Ctx, params *validation.QueryExchangeRate) ([]model.ExchangeRate, int64, error)
+	CreateExchangeRate(c *fiber.Ctx, req *validation.CreateExchangeRate) (*model.ExchangeRate, error)
+	ImportExchangeRates(c *fiber.Ctx, file io.Reader) (int, error)
+	DeleteExchangeRate(c *fiber.Ctx, id string) error
+}
+
+type exchangeRateService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewExchangeRateService(db *gorm.DB, validate *validator.Validate) ExchangeRateService {
+	return &exchangeRateService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *exchangeRateService) GetExchangeRates(
+	c *fiber.Ctx, params *validation.QueryExchangeRate,
+) ([]model.ExchangeRate, int64, error) {
+	var rates []model.ExchangeRate
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Model(&model.ExchangeRate{}).
+		Order("effective_date desc, from_currency asc, to_currency asc")
+
+	if currency := params.Currency; currency != "" {
+		query = query.Where("from_currency = ? OR to_currency = ?", currency, currency)
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count exchange rates: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	result = query.Limit(params.Limit).Offset(offset).Find(&rates)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get exchange rates: %+v", result.Error)
+		return nil, 0, result.Error
+	}
+
+	return rates, totalResults, nil
+}
+
+// CreateExchangeRate stores the rate of a currency pair for a day, replacing the rate
+// the pair already had that day. Spendings keep the rate they were converted at.
+func (s *exchangeRateService) CreateExchangeRate(
+	c *fiber.Ctx, req *validation.CreateExchangeRate,
+) (*model.ExchangeRate, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	rate, err := newExchangeRate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := upsertExchangeRates(s.DB.WithContext(c.Context()), []model.ExchangeRate{*rate}); err != nil {
+		s.Log.Errorf("Failed to create exchange rate: %+v", err)
+		return nil, err
+	}
+
+	stored := new(model.ExchangeRate)
+	if err := s.DB.WithContext(c.Context()).
+		First(stored, "from_currency = ? AND to_currency = ? AND effective_date = ?",
+			rate.FromCurrency, rate.ToCurrency, req.EffectiveDate).Error; err != nil {
+		s.Log.Errorf("Failed to get exchange rate: %+v", err)
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// ImportExchangeRates reads a CSV file with a from_currency, to_currency, rate and
+// effective_date header and stores every row, or none when a row is invalid
+func (s *exchangeRateService) ImportExchangeRates(c *fiber.Ctx, file io.Reader) (int, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil || !equalColumns(header, exchangeRateColumns) {
+		return 0, fiber.NewError(fiber.StatusBadRequest,
+			"The first line must be the header "+strings.Join(exchangeRateColumns, ","))
+	}
+
+	var rates []model.ExchangeRate
+	// One upsert cannot write a pair's rate of a day twice
+	lines := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Line %d: invalid CSV row", line))
+		}
+
+		req := &validation.CreateExchangeRate{
+			FromCurrency:  strings.ToUpper(record[0]),
+			ToCurrency:    strings.ToUpper(record[1]),
+			Rate:          record[2],
+			EffectiveDate: record[3],
+		}
+		if err := s.Validate.Struct(req); err != nil {
+			return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Line %d: %s", line, firstErrorMessage(err)))
+		}
+
+		rate, err := newExchangeRate(req)
+		if err != nil {
+			return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Line %d: %s", line, err.Error()))
+		}
+
+		key := rate.FromCurrency + rate.ToCurrency + rate.EffectiveDate.Format(time.DateOnly)
+		if first, found := lines[key]; found {
+			return 0, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Line %d: same currencies and effective_date as line %d", line, first))
+		}
+		lines[key] = line
+		rates = append(rates, *rate)
+	}
+
+	if len(rates) == 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "The file has no exchange rates")
+	}
+
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		return upsertExchangeRates(tx, rates)
+	})
+
+	if err != nil {
+		s.Log.Errorf("Failed to import exchange rates: %+v", err)
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func (s *exchangeRateService) DeleteExchangeRate(c *fiber.Ctx, id string) error {
+	result := s.DB.WithContext(c.Context()).Delete(&model.ExchangeRate{}, "id = ?", id)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to delete exchange rate: %+v", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Exchange rate not found")
+	}
+
+	return nil
+}
+
+func newExchangeRate(req *validation.CreateExchangeRate) (*model.ExchangeRate, error) {
+	rate, err := model.ParseRate(req.Rate)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid rate, "+err.Error())
+	}
+
+	date, err := time.Parse(time.DateOnly, req.EffectiveDate)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid effective_date, use YYYY-MM-DD")
+	}
+
+	return &model.ExchangeRate{
+		FromCurrency:  req.FromCurrency,
+		ToCurrency:    req.ToCurrency,
+		Rate:          model.FormatRate(rate),
+		EffectiveDate: date,
+	}, nil
+}
+
+// upsertExchangeRates writes rates, replacing the rate a pair already had on the same day
+func upsertExchangeRates(db *gorm.DB, rates []model.ExchangeRate) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}, {Name: "effective_date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"rate":       gorm.Expr("excluded.rate"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).CreateInBatches(&rates, 500).Error
+}
+
+// findExchangeRate returns how much of currency to one unit of from bought on the day of
+// at: the latest rate effective on or before that day. Rates stored for the opposite
+// direction count too and are inverted, on the same day the direct rate wins.
+func findExchangeRate(db *gorm.DB, from, to string, at time.Time) (*big.Rat, error) {
+	date := at.Format(time.DateOnly)
+
+	var rates []model.ExchangeRate
+	err := db.Raw(`
+		SELECT from_currency, to_currency, rate, effective_date FROM exchange_rates
+		WHERE ((from_currency = @from AND to_currency = @to) OR (from_currency = @to AND to_currency = @from))
+			AND effective_date <= @date
+		ORDER BY effective_date DESC, from_currency = @from DESC
+		LIMIT 1`,
+		map[string]interface{}{"from": from, "to": to, "date": date},
+	).Scan(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rates) == 0 {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity,
+			fmt.Sprintf("No exchange rate from %s to %s on or before %s", from, to, date))
+	}
+
+	rate, ok := new(big.Rat).SetString(rates[0].Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid stored exchange rate %q", rates[0].Rate)
+	}
+
+	if rates[0].FromCurrency != from {
+		rate.Inv(rate)
+	}
+
+	return rate, nil
+}
+
+func equalColumns(header, columns []string) bool {
+	if len(header) != len(columns) {
+		return false
+	}
+	for i := range columns {
+		if strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))) != columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstErrorMessage returns one readable message of a validation error
+func firstErrorMessage(err error) string {
+	messages := validation.CustomErrorMessages(err)
+
+	fields := make([]string, 0, len(messages))
+	for field := range messages {
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return err.Error()
+	}
+
+	sort.Strings(fields)
+	return messages[fields[0]]
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"app/src/config"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ExtractorAuto    = "auto"
+	ExtractorN8N     = "n8n"
+	ExtractorOffline = "offline"
+)
+
+// ExpenseInput is what a user sent to record a spending: free text or a receipt file
+type ExpenseInput struct {
+	Text          string
+	File          *ExpenseFile
+	UserSessionID string
+	Authorization string
+}
+
+type ExpenseFile struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// ExtractedExpense is the spending an extractor read from an ExpenseInput.
+// Confidence ranges from 0 to 1, extractors that cannot tell leave it at 0.
+// Receipts with several line items list them in Items, Amount is then the total.
+// Datetime is the transaction time when the extractor could read it.
+type ExtractedExpense struct {
+	Category   string
+	Name       string
+	Amount     float64
+	Confidence float64
+	Merchant   string
+	Datetime   *time.Time
+	Items      []ExtractedItem
+}
+
+type ExtractedItem struct {
+	Category string
+	Name     string
+	Amount   float64
+}
+
+// ExpenseExtractor turns free text or a receipt into a spending
+type ExpenseExtractor interface {
+	Extract(ctx context.Context, input *ExpenseInput) (*ExtractedExpense, error)
+}
+
+// NewExpenseExtractor returns the extractor registered under name. The default
+// parses text locally and only calls n8n for files or unclear messages.
+func NewExpenseExtractor(name string) (ExpenseExtractor, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ExtractorAuto:
+		return NewLocalFirstExtractor(NewN8NExtractor(config.N8NWebhookURL, config.N8NTimeout), config.ExpenseParserMinConfidence), nil
+	case ExtractorN8N:
+		return NewN8NExtractor(config.N8NWebhookURL, config.N8NTimeout), nil
+	case ExtractorOffline:
+		return NewOfflineExtractor(), nil
+	default:
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Unknown expense extractor %q", name))
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// amountPattern matches amounts such as "25rb", "100k", "1,5jt", "Rp 5.000" or "25000"
+var amountPattern = regexp.MustCompile(`(?i)(rp\.?\s*)?(\d+(?:[.,]\d+)*)\s*(ribu|rb|k|juta|jt)?\b`)
+
+// fillerWords are dropped from the spending name
+var fillerWords = map[string]struct{}{
+	"beli": {}, "bayar": {}, "buat": {}, "untuk": {}, "isi": {}, "habis": {}, "jajan": {}, "rp": {}, "rp.": {},
+}
+
+// categoryKeywords maps words commonly seen in spending messages to a category
+var categoryKeywords = map[string]string{
+	// food
+	"nasi": "Food", "makan": "Food", "bakso": "Food", "mie": "Food", "mi": "Food", "ayam": "Food",
+	"sate": "Food", "soto": "Food", "martabak": "Food", "gorengan": "Food", "roti": "Food",
+	"siomay": "Food", "pecel": "Food", "warteg": "Food", "padang": "Food", "geprek": "Food",
+	"burger": "Food", "pizza": "Food", "sarapan": "Food", "seblak": "Food", "gado-gado": "Food",
+	// drink
+	"kopi": "Drink", "teh": "Drink", "es": "Drink", "jus": "Drink", "boba": "Drink", "minum": "Drink",
+	"susu": "Drink",
+	// transport
+	"bensin": "Transport", "parkir": "Transport", "ojol": "Transport", "ojek": "Transport",
+	"gojek": "Transport", "grab": "Transport", "taksi": "Transport", "tol": "Transport",
+	"kereta": "Transport", "krl": "Transport", "busway": "Transport", "transjakarta": "Transport",
+	"pertalite": "Transport", "pertamax": "Transport", "angkot": "Transport",
+	// groceries
+	"indomaret": "Groceries", "alfamart": "Groceries", "sayur": "Groceries", "beras": "Groceries",
+	"telur": "Groceries", "minyak": "Groceries", "gula": "Groceries", "supermarket": "Groceries",
+	// bills
+	"listrik": "Bills", "pulsa": "Bills", "kuota": "Bills", "internet": "Bills", "wifi": "Bills",
+	"pdam": "Bills", "bpjs": "Bills", "cicilan": "Bills", "sewa": "Bills", "kos": "Bills",
+	// health
+	"obat": "Health", "apotek": "Health", "dokter": "Health", "klinik": "Health", "vitamin": "Health",
+	// entertainment
+	"nonton": "Entertainment", "bioskop": "Entertainment", "netflix": "Entertainment",
+	"spotify": "Entertainment", "game": "Entertainment", "karaoke": "Entertainment",
+	// shopping
+	"baju": "Shopping", "sepatu": "Shopping", "celana": "Shopping", "tas": "Shopping",
+	"shopee": "Shopping", "tokopedia": "Shopping",
+}
+
+var amountMultipliers = map[string]float64{
+	"rb": 1_000, "ribu": 1_000, "k": 1_000, "jt": 1_000_000, "juta": 1_000_000,
+}
+
+type amountMatch struct {
+	start, end int
+	value      float64
+	explicit   bool // written with a currency prefix, a suffix or thousand separators
+}
+
+// ParseExpenseText reads Indonesian free-text messages like "beli nasi goreng 25rb",
+// "bensin 100k" or "parkir 5.000". It returns nil when no amount is found. The
+// confidence tells how sure the parser is about the amount, name and category.
+func ParseExpenseText(text string) *ExtractedExpense {
+	matches := findAmounts(text)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// Prefer the last explicit amount, otherwise the last number in the text
+	amount := matches[len(matches)-1]
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i].explicit {
+			amount = matches[i]
+			break
+		}
+	}
+
+	name := spendingName(text[:amount.start] + " " + text[amount.end:])
+	category := categorize(name)
+
+	confidence := 0.35
+	if amount.explicit {
+		confidence = 0.5
+	}
+	if len(matches) == 1 {
+		confidence += 0.1
+	}
+	if name != "" {
+		confidence += 0.1
+	}
+	if category != "" {
+		confidence += 0.3
+	} else {
+		category = defaultCategory
+	}
+
+	if name == "" {
+		name = category
+	}
+
+	return &ExtractedExpense{
+		Category:   category,
+		Name:       name,
+		Amount:     amount.value,
+		Confidence: confidence,
+	}
+}
+
+// ParseExpenseLines reads a message with one spending per line (or separated
+// by ";") as the line items of a receipt. Messages with a single spending are
+// parsed by ParseExpenseText. Lines without an amount are skipped and the
+// confidence is the one of the least certain line.
+func ParseExpenseLines(text string) *ExtractedExpense {
+	lines := strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ';' })
+
+	var items []*ExtractedExpense
+	for _, line := range lines {
+		if item := ParseExpenseText(line); item != nil {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) <= 1 {
+		return ParseExpenseText(text)
+	}
+
+	expense := &ExtractedExpense{Confidence: 1}
+	for _, item := range items {
+		expense.Amount += item.Amount
+		expense.Confidence = min(expense.Confidence, item.Confidence)
+		expense.Items = append(expense.Items, ExtractedItem{
+			Category: item.Category,
+			Name:     item.Name,
+			Amount:   item.Amount,
+		})
+	}
+
+	// The largest item names the receipt when it is stored as a single spending
+	largest := items[0]
+	for _, item := range items[1:] {
+		if item.Amount > largest.Amount {
+			largest = item
+		}
+	}
+	expense.Category = largest.Category
+	expense.Name = largest.Name
+
+	return expense
+}
+
+func findAmounts(text string) []amountMatch {
+	var matches []amountMatch
+
+	for _, loc := range amountPattern.FindAllStringSubmatchIndex(text, -1) {
+		hasPrefix := loc[2] >= 0
+		number := text[loc[4]:loc[5]]
+		suffix := ""
+		if loc[6] >= 0 {
+			suffix = strings.ToLower(text[loc[6]:loc[7]])
+		}
+
+		value, grouped, ok := parseIndonesianNumber(number, suffix != "")
+		if !ok || value <= 0 {
+			continue
+		}
+
+		if multiplier, found := amountMultipliers[suffix]; found {
+			value *= multiplier
+		}
+
+		matches = append(matches, amountMatch{
+			start:    loc[0],
+			end:      loc[1],
+			value:    value,
+			explicit: hasPrefix || suffix != "" || grouped,
+		})
+	}
+
+	return matches
+}
+
+// parseIndonesianNumber parses "5.000", "1.250.000", "1,5" or "25,000". Dots are
+// thousand separators unless followed by fewer than three digits, commas are
+// decimal separators unless they group thousands in a number without a suffix.
+func parseIndonesianNumber(number string, hasSuffix bool) (float64, bool, bool) {
+	hasDot := strings.Contains(number, ".")
+	hasComma := strings.Contains(number, ",")
+
+	var normalized string
+	grouped := false
+
+	switch {
+	case hasDot && hasComma:
+		normalized = strings.ReplaceAll(number, ".", "")
+		normalized = strings.Replace(normalized, ",", ".", 1)
+		grouped = true
+	case hasDot:
+		if isThousandGrouped(number, ".") {
+			normalized = strings.ReplaceAll(number, ".", "")
+			grouped = true
+		} else {
+			normalized = number
+		}
+	case hasComma:
+		if !hasSuffix && isThousandGrouped(number, ",") {
+			normalized = strings.ReplaceAll(number, ",", "")
+			grouped = true
+		} else {
+			normalized = strings.Replace(number, ",", ".", 1)
+		}
+	default:
+		normalized = number
+	}
+
+	value, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, false, false
+	}
+
+	return value, grouped, true
+}
+
+func isThousandGrouped(number, separator string) bool {
+	groups := strings.Split(number, separator)
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+func spendingName(text string) string {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		word = strings.Trim(word, ",.:;-!?")
+		if word == "" {
+			continue
+		}
+		if _, filler := fillerWords[strings.ToLower(word)]; filler {
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// categorize returns the category with the most keyword hits, ties go to the first hit
+func categorize(name string) string {
+	hits := make(map[string]int)
+	best := ""
+
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		category, found := categoryKeywords[word]
+		if !found {
+			continue
+		}
+		hits[category]++
+		if best == "" || hits[category] > hits[best] {
+			best = category
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileStorage keeps uploaded files under opaque keys
+type FileStorage interface {
+	Save(key string, content []byte) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type localStorage struct {
+	Root string
+}
+
+// NewLocalStorage returns a FileStorage that writes files below root on the local filesystem
+func NewLocalStorage(root string) FileStorage {
+	return &localStorage{Root: root}
+}
+
+func (s *localStorage) Save(key string, content []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o640)
+}
+
+func (s *localStorage) Open(key string) (io.ReadCloser, error) {
+	return os.Open(s.path(key))
+}
+
+func (s *localStorage) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// path keeps keys inside the storage root
+func (s *localStorage) path(key string) string {
+	return filepath.Join(s.Root, filepath.Clean("/"+key))
+}
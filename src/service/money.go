@@ -0,0 +1,30 @@
+package service
+
+import (
+	"app/src/model"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseAmount reads a spending amount exactly in the given currency. Amounts must be positive.
+func parseAmount(value json.Number, currency string) (model.Money, error) {
+	amount, err := model.ParseMoney(string(value), currency)
+	if err != nil {
+		return model.Money{}, fiber.NewError(fiber.StatusBadRequest, "Invalid amount, "+err.Error())
+	}
+
+	if amount.Minor <= 0 {
+		return model.Money{}, fiber.NewError(fiber.StatusBadRequest, "Amount must be greater than zero")
+	}
+
+	return amount, nil
+}
+
+// currencyOr returns currency, or fallback when no currency was given
+func currencyOr(currency, fallback string) string {
+	if currency == "" {
+		return fallback
+	}
+	return currency
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"app/src/response"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type n8nExtractor struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewN8NExtractor returns an extractor that forwards the input to an n8n webhook and
+// gives up on it after timeout
+func NewN8NExtractor(webhookURL string, timeout time.Duration) ExpenseExtractor {
+	return &n8nExtractor{
+		WebhookURL: webhookURL,
+		Client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *n8nExtractor) Extract(ctx context.Context, input *ExpenseInput) (*ExtractedExpense, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if input.File != nil {
+		if err := writeFilePart(writer, input.File); err != nil {
+			return nil, err
+		}
+	} else if err := writer.WriteField("text", input.Text); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Cannot write text field")
+	}
+
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.WebhookURL, &buf)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create request")
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if input.UserSessionID != "" {
+		req.Header.Set("session_user_id", input.UserSessionID)
+	}
+	if input.Authorization != "" {
+		req.Header.Set("Authorization", input.Authorization)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Failed to send webhook")
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	// An error page would otherwise decode into an empty expense
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fiber.NewError(fiber.StatusBadGateway, fmt.Sprintf("Webhook responded with status %d", resp.StatusCode))
+	}
+
+	var wr response.WebhookResponse
+	if err := json.Unmarshal(body, &wr); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadGateway, "Failed to parse webhook response")
+	}
+
+	expense := &ExtractedExpense{
+		Category: wr.Category,
+		Name:     wr.Used,
+		Amount:   float64(wr.Total),
+		Merchant: wr.Merchant,
+	}
+
+	// A transaction time the webhook cannot format is dropped rather than failing the spending
+	if datetime, err := time.Parse(time.RFC3339, wr.Datetime); err == nil {
+		expense.Datetime = &datetime
+	}
+
+	for _, item := range wr.Items {
+		expense.Items = append(expense.Items, ExtractedItem{
+			Category: item.Category,
+			Name:     item.Used,
+			Amount:   float64(item.Total),
+		})
+	}
+
+	return expense, nil
+}
+
+func writeFilePart(writer *multipart.Writer, file *ExpenseFile) error {
+	mimeType := file.ContentType
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(file.Filename))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+	}
+
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, file.Filename))
+	partHeader.Set("Content-Type", mimeType)
+
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Cannot create form file")
+	}
+
+	if _, err := part.Write(file.Content); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Cannot copy file")
+	}
+
+	return nil
+}
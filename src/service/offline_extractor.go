@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultCategory = "Other"
+
+type offlineExtractor struct{}
+
+// NewOfflineExtractor returns an extractor that parses text locally, without
+// calling any external service
+func NewOfflineExtractor() ExpenseExtractor {
+	return &offlineExtractor{}
+}
+
+func (e *offlineExtractor) Extract(_ context.Context, input *ExpenseInput) (*ExtractedExpense, error) {
+	if input.File != nil {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Receipt files are not supported by the offline extractor")
+	}
+
+	expense := ParseExpenseLines(input.Text)
+	if expense == nil {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Cannot find an amount in the text")
+	}
+
+	return expense, nil
+}
+
+type localFirstExtractor struct {
+	Fallback      ExpenseExtractor
+	MinConfidence float64
+}
+
+// NewLocalFirstExtractor returns an extractor that parses text locally and uses
+// fallback for files and for text parsed with less than minConfidence
+func NewLocalFirstExtractor(fallback ExpenseExtractor, minConfidence float64) ExpenseExtractor {
+	return &localFirstExtractor{
+		Fallback:      fallback,
+		MinConfidence: minConfidence,
+	}
+}
+
+func (e *localFirstExtractor) Extract(ctx context.Context, input *ExpenseInput) (*ExtractedExpense, error) {
+	if input.File == nil {
+		if expense := ParseExpenseLines(input.Text); expense != nil && expense.Confidence >= e.MinConfidence {
+			return expense, nil
+		}
+	}
+
+	return e.Fallback.Extract(ctx, input)
+}
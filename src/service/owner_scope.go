@@ -0,0 +1,88 @@
+package service
+
+import (
+	"app/src/model"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OwnedBy limits a query on spendings or category_spending_summaries to rows
+// belonging to the given user sessions. An empty list matches nothing.
+func OwnedBy(userSessionIDs ...uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	values := make([]interface{}, len(userSessionIDs))
+	for i, id := range userSessionIDs {
+		values[i] = id
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.IN{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "user_session_id"},
+			Values: values,
+		})
+	}
+}
+
+// withOwner runs fn in a transaction whose row-level security policies only
+// expose rows of the given user sessions. Queries inside fn should still use
+// OwnedBy so the filter holds when connected as the table owner.
+func withOwner(db *gorm.DB, userSessionIDs []uuid.UUID, fn func(tx *gorm.DB) error) error {
+	ids := make([]string, len(userSessionIDs))
+	for i, id := range userSessionIDs {
+		ids[i] = id.String()
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('app.user_session_ids', ?, true)", strings.Join(ids, ",")).Error; err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// withoutOwner runs fn in a transaction whose row-level security policies expose the
+// rows of every session. Outside of withOwner and withoutOwner the policies hide every
+// row, so this is only for work spanning users: the summary worker, rebuilds and
+// changes to shared categories.
+func withoutOwner(db *gorm.DB, fn func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('app.bypass_owner', 'on', true)").Error; err != nil {
+			return err
+		}
+
+		return fn(tx)
+	}, opts...)
+}
+
+// ownerSessionIDs returns the user's own session id followed by the sessions they claimed
+func ownerSessionIDs(db *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	var linked []uuid.UUID
+	if err := db.Model(&model.UserSession{}).
+		Where("user_id = ?", userID).
+		Pluck("user_session_id", &linked).Error; err != nil {
+		return nil, err
+	}
+
+	return append([]uuid.UUID{userID}, linked...), nil
+}
+
+// sessionOwnerIDs returns the session ids of whoever owns a session: the user who
+// claimed it followed by all their sessions, or the unclaimed session alone
+func sessionOwnerIDs(db *gorm.DB, userSessionID uuid.UUID) ([]uuid.UUID, error) {
+	var claimedBy []uuid.UUID
+	if err := db.Model(&model.UserSession{}).
+		Where("user_session_id = ?", userSessionID).
+		Pluck("user_id", &claimedBy).Error; err != nil {
+		return nil, err
+	}
+
+	if len(claimedBy) > 0 {
+		userSessionID = claimedBy[0]
+	}
+
+	return ownerSessionIDs(db, userSessionID)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionPreferences are the settings of the user owning a session that its summaries
+// follow. Unclaimed sessions use APP_TIMEZONE and CURRENCY.
+type sessionPreferences struct {
+	Location     *time.Location
+	BaseCurrency string
+}
+
+// loadSessionPreferences returns the preferences of the user with the given id or of
+// the user who claimed the session
+func loadSessionPreferences(db *gorm.DB, userSessionID uuid.UUID) (*sessionPreferences, error) {
+	var users []model.User
+	err := db.Raw(`
+		SELECT timezone, base_currency FROM users
+		WHERE id = @session OR id = (SELECT user_id FROM user_sessions WHERE user_session_id = @session)`,
+		map[string]interface{}{"session": userSessionID},
+	).Scan(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{}
+	if len(users) > 0 {
+		user = users[0]
+	}
+
+	loc, err := LoadLocation(user.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := &sessionPreferences{Location: loc, BaseCurrency: user.BaseCurrency}
+	if prefs.BaseCurrency == "" {
+		prefs.BaseCurrency = config.Currency
+	}
+
+	return prefs, nil
+}
+
+// toBase converts an amount into the base currency at the rate of the day it was spent
+func (p *sessionPreferences) toBase(db *gorm.DB, amount model.Money, at time.Time) (model.Money, string, error) {
+	if amount.Currency == p.BaseCurrency {
+		return amount, "1", nil
+	}
+
+	rate, err := findExchangeRate(db, amount.Currency, p.BaseCurrency, at.In(p.Location))
+	if err != nil {
+		return model.Money{}, "", err
+	}
+
+	return amount.Convert(p.BaseCurrency, rate), model.FormatRate(rate), nil
+}
+
+// convertSpending sets the spending's base amount and the exchange rate it was converted at
+func (p *sessionPreferences) convertSpending(db *gorm.DB, spending *model.Spending) error {
+	base, rate, err := p.toBase(db, spending.Amount, spending.Datetime)
+	if err != nil {
+		return err
+	}
+
+	spending.BaseAmount = base
+	spending.ExchangeRate = rate
+	return nil
+}
+
+// sessionPreferenceCache caches loadSessionPreferences for jobs touching many sessions
+type sessionPreferenceCache struct {
+	db    *gorm.DB
+	prefs map[uuid.UUID]*sessionPreferences
+}
+
+func newSessionPreferenceCache(db *gorm.DB) *sessionPreferenceCache {
+	return &sessionPreferenceCache{db: db, prefs: make(map[uuid.UUID]*sessionPreferences)}
+}
+
+func (c *sessionPreferenceCache) of(userSessionID uuid.UUID) (*sessionPreferences, error) {
+	if prefs, ok := c.prefs[userSessionID]; ok {
+		return prefs, nil
+	}
+
+	prefs, err := loadSessionPreferences(c.db, userSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.prefs[userSessionID] = prefs
+	return prefs, nil
+}
+
+// requestPreferences resolves the timezone a request is computed in and the preferences
+// the owner's summaries are kept in. The first owner session is the user's own.
+func requestPreferences(db *gorm.DB, timezone string, owner []uuid.UUID) (*time.Location, *sessionPreferences, error) {
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefs, err := loadSessionPreferences(db, owner[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return loc, prefs, nil
+}
+
+// convertOwnerSpendings converts the spendings of a user and their claimed sessions into
+// prefs.BaseCurrency, at the rate of the day each was spent
+func convertOwnerSpendings(tx *gorm.DB, userID uuid.UUID, prefs *sessionPreferences) error {
+	owner, err := ownerSessionIDs(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	var spendings []model.Spending
+	return withOwner(tx, owner, func(tx *gorm.DB) error {
+		return tx.Scopes(OwnedBy(owner...)).
+			FindInBatches(&spendings, 1000, func(_ *gorm.DB, _ int) error {
+				for i := range spendings {
+					before := spendings[i].BaseAmount
+					if err := prefs.convertSpending(tx, &spendings[i]); err != nil {
+						return err
+					}
+					if spendings[i].BaseAmount == before {
+						continue
+					}
+
+					if err := tx.Model(&spendings[i]).UpdateColumns(map[string]interface{}{
+						"base_amount_minor":    spendings[i].BaseAmount.Minor,
+						"base_amount_currency": spendings[i].BaseAmount.Currency,
+						"exchange_rate":        spendings[i].ExchangeRate,
+					}).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+	})
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionService interface {
+	GetSessions(c *fiber.Ctx, userID string) ([]model.UserSession, error)
+	GetUserSessionIDs(c *fiber.Ctx, userID string) ([]uuid.UUID, error)
+	ClaimSession(c *fiber.Ctx, req *validation.ClaimSession, userID string) (*model.UserSession, error)
+	CreateClaimCode(c *fiber.Ctx, req *validation.CreateSessionClaimCode) (*response.SessionClaimCode, error)
+	GetChannelSessionCurrency(c *fiber.Ctx, userSessionID string) (string, error)
+}
+
+// claimCodeAlphabet leaves out characters that are easy to misread in a chat
+const claimCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const claimCodeLength = 8
+
+type sessionService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSessionService(db *gorm.DB, validate *validator.Validate) SessionService {
+	return &sessionService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *sessionService) GetSessions(c *fiber.Ctx, userID string) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+
+	result := s.DB.WithContext(c.Context()).
+		Where("user_id = ?", userID).
+		Order("created_at asc").
+		Find(&sessions)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get user sessions: %+v", result.Error)
+	}
+
+	return sessions, result.Error
+}
+
+// GetUserSessionIDs returns every session id owned by the user. The user id itself
+// is the session used for spendings recorded through the API.
+func (s *sessionService) GetUserSessionIDs(c *fiber.Ctx, userID string) ([]uuid.UUID, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var linked []uuid.UUID
+	result := s.DB.WithContext(c.Context()).
+		Model(&model.UserSession{}).
+		Where("user_id = ?", userID).
+		Pluck("user_session_id", &linked)
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get user session ids: %+v", result.Error)
+		return nil, result.Error
+	}
+
+	return append([]uuid.UUID{userUUID}, linked...), nil
+}
+
+// ClaimSession links a chat-channel session to the user once they prove it is theirs
+// with the code the channel sent into it. Spendings recorded under that session become
+// visible to the user from then on.
+func (s *sessionService) ClaimSession(c *fiber.Ctx, req *validation.ClaimSession, userID string) (*model.UserSession, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	sessionUUID := uuid.MustParse(req.UserSessionID)
+	if sessionUUID == userUUID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Session already belongs to you")
+	}
+
+	existing := new(model.UserSession)
+	result := s.DB.WithContext(c.Context()).First(existing, "user_session_id = ?", sessionUUID)
+
+	if result.Error == nil {
+		if existing.UserID != userUUID {
+			return nil, fiber.NewError(fiber.StatusConflict, "Session is already claimed by another user")
+		}
+		return existing, nil
+	}
+
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		s.Log.Errorf("Failed to get user session: %+v", result.Error)
+		return nil, result.Error
+	}
+
+	// A user's own id is their API session, it can never be claimed by someone else
+	var users int64
+	if err := s.DB.WithContext(c.Context()).Model(&model.User{}).Where("id = ?", sessionUUID).Count(&users).Error; err != nil {
+		s.Log.Errorf("Failed to check user session: %+v", err)
+		return nil, err
+	}
+
+	if users > 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "Session is already claimed by another user")
+	}
+
+	session := &model.UserSession{
+		UserID:        userUUID,
+		UserSessionID: sessionUUID,
+	}
+
+	// The session's spendings and summaries were kept in the default timezone and currency
+	// until now, they move to the user's together with the claim
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := lockSummaries(tx); err != nil {
+			return err
+		}
+
+		code, err := useClaimCode(tx, sessionUUID, req.Code)
+		if err != nil {
+			return err
+		}
+		session.Channel = code.Channel
+
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+
+		prefs, err := loadSessionPreferences(tx, userUUID)
+		if err != nil {
+			return err
+		}
+
+		if err := convertOwnerSpendings(tx, userUUID, prefs); err != nil {
+			return err
+		}
+
+		return rebuildUserSummaries(tx, userUUID)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Session is already claimed by another user")
+	}
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to claim user session: %+v", err)
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// CreateClaimCode issues the code the chat channel sends into a session so its user can
+// claim it. A new code replaces the session's previous one.
+func (s *sessionService) CreateClaimCode(
+	c *fiber.Ctx, req *validation.CreateSessionClaimCode,
+) (*response.SessionClaimCode, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	sessionUUID := uuid.MustParse(req.UserSessionID)
+
+	code, err := newClaimCode()
+	if err != nil {
+		s.Log.Errorf("Failed to generate claim code: %+v", err)
+		return nil, err
+	}
+
+	claimCode := &model.SessionClaimCode{
+		UserSessionID: sessionUUID,
+		Channel:       req.Channel,
+		CodeHash:      hashClaimCode(code),
+		ExpiresAt:     time.Now().Add(time.Duration(config.SessionClaimCodeExp) * time.Minute),
+	}
+
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		var claimed int64
+		if err := tx.Model(&model.UserSession{}).Where("user_session_id = ?", sessionUUID).Count(&claimed).Error; err != nil {
+			return err
+		}
+		var users int64
+		if err := tx.Model(&model.User{}).Where("id = ?", sessionUUID).Count(&users).Error; err != nil {
+			return err
+		}
+		if claimed > 0 || users > 0 {
+			return fiber.NewError(fiber.StatusConflict, "Session is already claimed")
+		}
+
+		if err := tx.Delete(&model.SessionClaimCode{}, "user_session_id = ?", sessionUUID).Error; err != nil {
+			return err
+		}
+		return tx.Create(claimCode).Error
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create claim code: %+v", err)
+		}
+		return nil, err
+	}
+
+	return &response.SessionClaimCode{
+		UserSessionID: sessionUUID,
+		Channel:       claimCode.Channel,
+		Code:          code,
+		ExpiresAt:     claimCode.ExpiresAt,
+	}, nil
+}
+
+// GetChannelSessionCurrency returns the base currency of a chat session: its claiming
+// user's, else the default. A user's own id is their API session, the channel cannot
+// record spendings into it.
+func (s *sessionService) GetChannelSessionCurrency(c *fiber.Ctx, userSessionID string) (string, error) {
+	sessionUUID, err := uuid.Parse(userSessionID)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid user session ID")
+	}
+
+	var users int64
+	if err := s.DB.WithContext(c.Context()).Model(&model.User{}).Where("id = ?", sessionUUID).Count(&users).Error; err != nil {
+		s.Log.Errorf("Failed to check user session: %+v", err)
+		return "", err
+	}
+
+	if users > 0 {
+		return "", fiber.NewError(fiber.StatusForbidden, "Session is not a chat session")
+	}
+
+	prefs, err := loadSessionPreferences(s.DB.WithContext(c.Context()), sessionUUID)
+	if err != nil {
+		s.Log.Errorf("Failed to get session preferences: %+v", err)
+		return "", err
+	}
+
+	return prefs.BaseCurrency, nil
+}
+
+// useClaimCode consumes the session's code when it matches and has not expired
+func useClaimCode(tx *gorm.DB, userSessionID uuid.UUID, code string) (*model.SessionClaimCode, error) {
+	claimCode := new(model.SessionClaimCode)
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_session_id = ? AND expires_at > ?", userSessionID, time.Now()).
+		Limit(1).
+		Find(claimCode)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	expected := []byte(claimCode.CodeHash)
+	given := []byte(hashClaimCode(code))
+	if result.RowsAffected == 0 || subtle.ConstantTimeCompare(expected, given) != 1 {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Invalid or expired claim code")
+	}
+
+	return claimCode, tx.Delete(claimCode).Error
+}
+
+func newClaimCode() (string, error) {
+	random := make([]byte, claimCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, claimCodeLength)
+	for i, b := range random {
+		code[i] = claimCodeAlphabet[int(b)%len(claimCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// hashClaimCode returns the stored form of a code, ignoring case and surrounding spaces
+func hashClaimCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
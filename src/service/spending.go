@@ -5,6 +5,7 @@ import (
 	"app/src/response"
 	"app/src/utils"
 	"app/src/validation"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -13,27 +14,46 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SpendingService interface {
 	CreateSpending(c *fiber.Ctx, req *validation.CreateSpending) (*model.Spending, error)
-	GetCategories(c *fiber.Ctx, params *validation.QueryUser) ([]model.Category, int64, error)
-	GetSpendings(c *fiber.Ctx, params *validation.QueryUser) ([]model.Spending, int64, error)
+	CreateReceipt(c *fiber.Ctx, req *validation.CreateReceipt) (*model.Receipt, error)
+	GetCategories(c *fiber.Ctx, params *validation.QuerySpendingCategory) ([]model.Category, int64, error)
+	GetCategoryTree(c *fiber.Ctx, params *validation.QuerySpendingCategory) ([]response.CategoryNode, error)
+	GetSpendings(c *fiber.Ctx, params *validation.QuerySpending) ([]model.Spending, int64, error)
 	GetSummarySpending(c *fiber.Ctx, params *validation.QuerySpendingSummary) ([]model.CategorySpendingSummary, int64, error)
 	GetSummaryTotal(c *fiber.Ctx, params *validation.QuerySpendingSummary) (response.TotalSummarySpending, error)
+	GetTimeseries(c *fiber.Ctx, params *validation.QuerySpendingTimeseries) (*response.SpendingTimeseries, error)
+	GetComparison(c *fiber.Ctx, params *validation.QuerySpendingComparison) (*response.SpendingComparison, error)
+	GetSpendingByID(c *fiber.Ctx, id, userID string) (*model.Spending, error)
+	UpdateSpending(c *fiber.Ctx, req *validation.UpdateSpending, id, userID string) (*model.Spending, error)
+	ConfirmSpending(c *fiber.Ctx, req *validation.UpdateSpending, id, userID string) (*model.Spending, error)
+	DeleteSpending(c *fiber.Ctx, id, userID string) error
+	RejectSpending(c *fiber.Ctx, id, userID string) error
 }
 
 type spendingService struct {
-	Log      *logrus.Logger
-	DB       *gorm.DB
-	Validate *validator.Validate
+	Log            *logrus.Logger
+	DB             *gorm.DB
+	Validate       *validator.Validate
+	SessionService SessionService
+	Storage        FileStorage
+	SummaryWorker  SummaryWorker
 }
 
-func NewSpendingService(db *gorm.DB, validate *validator.Validate) SpendingService {
+func NewSpendingService(
+	db *gorm.DB, validate *validator.Validate, sessionService SessionService, storage FileStorage,
+	summaryWorker SummaryWorker,
+) SpendingService {
 	return &spendingService{
-		Log:      utils.Log,
-		DB:       db,
-		Validate: validate,
+		Log:            utils.Log,
+		DB:             db,
+		Validate:       validate,
+		SessionService: sessionService,
+		Storage:        storage,
+		SummaryWorker:  summaryWorker,
 	}
 }
 
@@ -47,49 +67,173 @@ func (s *spendingService) CreateSpending(c *fiber.Ctx, req *validation.CreateSpe
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid UserSessionID format")
 	}
 
-	// Ensure category exists or create it if not
-	category := model.Category{Name: req.Category}
-	if err := s.DB.WithContext(c.Context()).FirstOrCreate(&category, model.Category{Name: req.Category}).Error; err != nil {
-		s.Log.Errorf("Failed to get or create category: %+v", err)
-		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get or create category")
+	// The transaction datetime decides which summary periods the spending lands in
+	parsedDatetime, err := utils.ParseDatetime(req.Datetime)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid datetime format")
+	}
+
+	// Amounts without a currency are in the base currency of the session's owner
+	prefs, err := loadSessionPreferences(s.DB.WithContext(c.Context()), userSessionUUID)
+	if err != nil {
+		s.Log.Errorf("Failed to get session preferences: %+v", err)
+		return nil, err
+	}
+
+	amount, err := parseAmount(req.Amount, currencyOr(req.Currency, prefs.BaseCurrency))
+	if err != nil {
+		return nil, err
 	}
-	// Use current time for spending.Datetime
-	parsedDatetime := time.Now()
 
 	spending := &model.Spending{
 		UserSessionID: userSessionUUID, // userSessionUUID should be uuid.UUID type
-		Amount:        req.Amount,
+		Amount:        amount,
 		Name:          req.Name,
 		Description:   req.Description,
-		Category:      req.Category,
+		Merchant:      req.Merchant,
 		Datetime:      parsedDatetime,
-		CategoryID:    &category.ID, // Pass pointer to uuid.UUID
+		IsConfirm:     req.IsConfirm,
 	}
 
-	result := s.DB.WithContext(c.Context()).Create(spending)
+	// The summary update is queued in the same transaction, so it is never lost
+	err = withOwner(s.DB.WithContext(c.Context()), []uuid.UUID{userSessionUUID}, func(tx *gorm.DB) error {
+		// Rules with an amount range match the converted amount too
+		if err := prefs.convertSpending(tx, spending); err != nil {
+			return err
+		}
+
+		// The owner's rules override the category the extractor gave
+		categorizer, err := newSpendingCategorizer(tx, userSessionUUID)
+		if err != nil {
+			return err
+		}
+		if err := categorizer.categorize(tx, spending, req.Category); err != nil {
+			return err
+		}
 
-	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		if err := tx.Create(spending).Error; err != nil {
+			return err
+		}
+
+		// Pending spendings only count toward the summaries once they are confirmed
+		return queueSpendingCreated(tx, spending)
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
 		return nil, fiber.NewError(fiber.StatusConflict, "Spending record already exists")
 	}
 
-	if result.Error != nil {
-		s.Log.Errorf("Failed to create spending: %+v", result.Error)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create spending: %+v", err)
+		}
+		return nil, err
+	}
+
+	s.SummaryWorker.Notify()
+
+	return spending, nil
+}
+
+// CreateReceipt stores a receipt with one spending per line item. The receipt,
+// its spendings and their queued summary updates are written in a single transaction.
+func (s *spendingService) CreateReceipt(c *fiber.Ctx, req *validation.CreateReceipt) (*model.Receipt, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	userSessionUUID, err := utils.ParseUUID(req.UserSessionID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid UserSessionID format")
+	}
+
+	datetime, err := utils.ParseDatetime(req.Datetime)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid datetime format")
 	}
 
-	// Call UpsertSummary to update weekly and monthly summaries in background
-	go func(db *gorm.DB, userSessionID uuid.UUID, categoryID uuid.UUID, categoryName string, amount int64, log *logrus.Logger, ctx fiber.Ctx) {
-		// Use the same context for DB
-		err := UpsertSummary(db.WithContext(ctx.Context()), userSessionID, categoryID, categoryName, amount)
+	prefs, err := loadSessionPreferences(s.DB.WithContext(c.Context()), userSessionUUID)
+	if err != nil {
+		s.Log.Errorf("Failed to get session preferences: %+v", err)
+		return nil, err
+	}
+
+	amounts := make([]model.Money, len(req.Items))
+	for i, item := range req.Items {
+		if amounts[i], err = parseAmount(item.Amount, currencyOr(req.Currency, prefs.BaseCurrency)); err != nil {
+			return nil, err
+		}
+	}
+
+	receipt := &model.Receipt{
+		UserSessionID: userSessionUUID,
+		Merchant:      req.Merchant,
+		Total:         model.NewMoney(0, amounts[0].Currency),
+		Datetime:      datetime,
+	}
+	for _, amount := range amounts {
+		receipt.Total.Minor += amount.Minor
+	}
+
+	err = withOwner(s.DB.WithContext(c.Context()), []uuid.UUID{userSessionUUID}, func(tx *gorm.DB) error {
+		if err := tx.Omit("Spendings").Create(receipt).Error; err != nil {
+			return err
+		}
+
+		categorizer, err := newSpendingCategorizer(tx, userSessionUUID)
 		if err != nil {
-			log.Errorf("Failed to upsert summary: %+v", err)
-			// Tidak perlu return error jika summary gagal — opsional
+			return err
+		}
+
+		for i, item := range req.Items {
+			spending := model.Spending{
+				UserSessionID: userSessionUUID,
+				Amount:        amounts[i],
+				Name:          item.Name,
+				Description:   item.Description,
+				Merchant:      receipt.Merchant,
+				Datetime:      receipt.Datetime,
+				ReceiptID:     &receipt.ID,
+				IsConfirm:     req.IsConfirm,
+			}
+
+			if err := prefs.convertSpending(tx, &spending); err != nil {
+				return err
+			}
+
+			if err := categorizer.categorize(tx, &spending, item.Category); err != nil {
+				return err
+			}
+
+			if err := tx.Create(&spending).Error; err != nil {
+				return err
+			}
+
+			if err := queueSpendingCreated(tx, &spending); err != nil {
+				return err
+			}
+
+			receipt.Spendings = append(receipt.Spendings, spending)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to create receipt: %+v", err)
 		}
-	}(s.DB, userSessionUUID, category.ID, category.Name, int64(req.Amount), s.Log, *c)
+		return nil, err
+	}
+
+	s.SummaryWorker.Notify()
 
-	return spending, result.Error
+	return receipt, nil
 }
 
-func (s *spendingService) GetSpendings(c *fiber.Ctx, params *validation.QueryUser) ([]model.Spending, int64, error) {
+func (s *spendingService) GetSpendings(c *fiber.Ctx, params *validation.QuerySpending) ([]model.Spending, int64, error) {
 	var spendings []model.Spending
 	var totalResults int64
 
@@ -97,31 +241,379 @@ func (s *spendingService) GetSpendings(c *fiber.Ctx, params *validation.QueryUse
 		return nil, 0, err
 	}
 
+	owner, err := s.SessionService.GetUserSessionIDs(c, params.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	offset := (params.Page - 1) * params.Limit
-	query := s.DB.WithContext(c.Context()).Model(&model.Spending{}).Order("created_at asc")
 
-	if search := params.Search; search != "" {
-		query = query.Where("name LIKE ? OR description LIKE ? OR category LIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		query := tx.Model(&model.Spending{}).Scopes(OwnedBy(owner...)).Order("created_at asc")
+
+		switch params.Status {
+		case "pending":
+			query = query.Where("is_confirm = ?", false)
+		case "confirmed":
+			query = query.Where("is_confirm = ?", true)
+		}
+
+		if search := params.Search; search != "" {
+			query = query.Where("(name LIKE ? OR description LIKE ? OR category LIKE ?)",
+				"%"+search+"%", "%"+search+"%", "%"+search+"%")
+		}
+
+		result := query.Count(&totalResults)
+		if result.Error != nil {
+			s.Log.Errorf("Failed to count spendings: %+v", result.Error)
+			return result.Error
+		}
+
+		result = query.Limit(params.Limit).Offset(offset).Find(&spendings)
+		if result.Error != nil {
+			s.Log.Errorf("Failed to get spendings: %+v", result.Error)
+		}
+		return result.Error
+	})
+
+	if err != nil {
+		return nil, 0, err
 	}
 
-	result := query.Count(&totalResults)
-	if result.Error != nil {
-		s.Log.Errorf("Failed to count spendings: %+v", result.Error)
-		return nil, 0, result.Error
+	return spendings, totalResults, nil
+}
+
+func (s *spendingService) GetSpendingByID(c *fiber.Ctx, id, userID string) (*model.Spending, error) {
+	owner, err := s.SessionService.GetUserSessionIDs(c, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	result = query.Limit(params.Limit).Offset(offset).Find(&spendings)
-	if result.Error != nil {
-		s.Log.Errorf("Failed to get spendings: %+v", result.Error)
-		return nil, 0, result.Error
+	spending := new(model.Spending)
+
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		return tx.Scopes(OwnedBy(owner...)).First(spending, "id = ?", id).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Spending not found")
 	}
 
-	return spendings, totalResults, nil
+	if err != nil {
+		s.Log.Errorf("Failed get spending by id: %+v", err)
+	}
+
+	return spending, err
 }
 
-// GetCategories implements SpendingService.
-func (s *spendingService) GetCategories(c *fiber.Ctx, params *validation.QueryUser) ([]model.Category, int64, error) {
+func (s *spendingService) UpdateSpending(c *fiber.Ctx, req *validation.UpdateSpending, id, userID string) (*model.Spending, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if req.Category == "" && req.Name == "" && req.Amount == "" && req.Currency == "" &&
+		req.Description == "" && req.Datetime == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	return s.updateSpending(c, req, id, userID, false)
+}
+
+// ConfirmSpending applies the optional corrections in req to a pending spending
+// and confirms it, adding it to the summaries
+func (s *spendingService) ConfirmSpending(c *fiber.Ctx, req *validation.UpdateSpending, id, userID string) (*model.Spending, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	return s.updateSpending(c, req, id, userID, true)
+}
+
+func (s *spendingService) updateSpending(
+	c *fiber.Ctx, req *validation.UpdateSpending, id, userID string, confirm bool,
+) (*model.Spending, error) {
+	owner, err := s.SessionService.GetUserSessionIDs(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	spending := new(model.Spending)
+
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(OwnedBy(owner...)).
+			First(spending, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Spending not found")
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if confirm && spending.IsConfirm {
+			return fiber.NewError(fiber.StatusConflict, "Spending is already confirmed")
+		}
+
+		previous := *spending
+
+		if confirm {
+			spending.IsConfirm = true
+		}
+		if req.Category != "" && req.Category != spending.Category {
+			category, err := s.firstOrCreateCategory(tx, spending.UserSessionID, req.Category)
+			if err != nil {
+				return err
+			}
+			if err := recordCategoryCorrection(tx, owner[0], &previous, category); err != nil {
+				return err
+			}
+			spending.Category = category.Name
+			spending.CategoryID = &category.ID
+		}
+		if req.Name != "" {
+			spending.Name = req.Name
+		}
+		if req.Amount != "" || req.Currency != "" {
+			// A new currency alone keeps the amount, e.g. 15.50 IDR that was meant as SGD
+			value := req.Amount
+			if value == "" {
+				value = json.Number(spending.Amount.String())
+			}
+			amount, err := parseAmount(value, currencyOr(req.Currency, spending.Amount.Currency))
+			if err != nil {
+				return err
+			}
+			spending.Amount = amount
+		}
+		if req.Description != "" {
+			spending.Description = req.Description
+		}
+		if req.Datetime != "" {
+			datetime, err := utils.ParseDatetime(req.Datetime)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Invalid datetime format")
+			}
+			spending.Datetime = datetime
+		}
+
+		// The rate follows the transaction date, so convert again after every edit
+		prefs, err := loadSessionPreferences(tx, spending.UserSessionID)
+		if err != nil {
+			return err
+		}
+		if err := prefs.convertSpending(tx, spending); err != nil {
+			return err
+		}
+
+		if err := tx.Save(spending).Error; err != nil {
+			return err
+		}
+
+		if !summaryChanged(&previous, spending) {
+			return nil
+		}
+
+		// Move the amount out of the periods it was counted in and into the new ones
+		if err := applySpendingSummary(tx, &previous, -1); err != nil {
+			return err
+		}
+		return applySpendingSummary(tx, spending, 1)
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to update spending: %+v", err)
+		}
+		return nil, err
+	}
+
+	return spending, nil
+}
+
+func (s *spendingService) DeleteSpending(c *fiber.Ctx, id, userID string) error {
+	return s.deleteSpending(c, id, userID, false)
+}
+
+// RejectSpending deletes a spending that is still pending
+func (s *spendingService) RejectSpending(c *fiber.Ctx, id, userID string) error {
+	return s.deleteSpending(c, id, userID, true)
+}
+
+func (s *spendingService) deleteSpending(c *fiber.Ctx, id, userID string, pendingOnly bool) error {
+	owner, err := s.SessionService.GetUserSessionIDs(c, userID)
+	if err != nil {
+		return err
+	}
+
+	var attachmentKeys []string
+
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		spending := new(model.Spending)
+
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(OwnedBy(owner...)).
+			First(spending, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Spending not found")
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if pendingOnly && spending.IsConfirm {
+			return fiber.NewError(fiber.StatusConflict, "Spending is already confirmed")
+		}
+
+		if err := tx.Model(&model.Attachment{}).
+			Where("spending_id = ?", spending.ID).
+			Pluck("storage_key", &attachmentKeys).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("spending_id = ?", spending.ID).Delete(&model.Attachment{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(spending).Error; err != nil {
+			return err
+		}
+
+		if err := applySpendingSummary(tx, spending, -1); err != nil {
+			return err
+		}
+
+		if spending.ReceiptID == nil {
+			return nil
+		}
+
+		receiptKeys, err := s.deleteEmptyReceipt(tx, *spending.ReceiptID)
+		attachmentKeys = append(attachmentKeys, receiptKeys...)
+		return err
+	})
+
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to delete spending: %+v", err)
+		}
+		return err
+	}
+
+	// Files are only removed once the rows are gone for good
+	s.removeFiles(attachmentKeys)
+
+	return nil
+}
+
+// deleteEmptyReceipt removes a receipt and its attachments once its last spending
+// is gone, returning the storage keys of the removed attachments
+func (s *spendingService) deleteEmptyReceipt(tx *gorm.DB, receiptID uuid.UUID) ([]string, error) {
+	var remaining int64
+	if err := tx.Model(&model.Spending{}).Where("receipt_id = ?", receiptID).Count(&remaining).Error; err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, nil
+	}
+
+	var keys []string
+	if err := tx.Model(&model.Attachment{}).
+		Where("receipt_id = ?", receiptID).
+		Pluck("storage_key", &keys).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("receipt_id = ?", receiptID).Delete(&model.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, tx.Delete(&model.Receipt{}, "id = ?", receiptID).Error
+}
+
+// removeFiles deletes stored files, failures only leave orphaned files behind
+func (s *spendingService) removeFiles(keys []string) {
+	for _, key := range keys {
+		if err := s.Storage.Delete(key); err != nil {
+			s.Log.Errorf("Failed to remove attachment file %s: %+v", key, err)
+		}
+	}
+}
+
+// firstOrCreateCategory returns the category with the given name, creating it when missing.
+// Deactivated categories take no new spendings.
+func (s *spendingService) firstOrCreateCategory(db *gorm.DB, userSessionID uuid.UUID, name string) (*model.Category, error) {
+	owner, err := sessionOwnerIDs(db, userSessionID)
+	if err != nil {
+		s.Log.Errorf("Failed to get category owner: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get or create category")
+	}
+
+	category, err := findOrCreateCategory(db, owner, name)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to get or create category: %+v", err)
+			err = fiber.NewError(fiber.StatusInternalServerError, "Failed to get or create category")
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// summaryChanged reports whether an edit moves a spending to other summary rows, changes
+// its base amount or confirms it
+func summaryChanged(before, after *model.Spending) bool {
+	return before.IsConfirm != after.IsConfirm ||
+		before.BaseAmount != after.BaseAmount ||
+		!before.Datetime.Equal(after.Datetime) ||
+		categoryIDOf(before) != categoryIDOf(after)
+}
+
+// applySpendingSummary adds (sign 1) or removes (sign -1) a spending from its summary
+// periods. Pending spendings are not part of the summaries.
+func applySpendingSummary(db *gorm.DB, spending *model.Spending, sign int64) error {
+	if !spending.IsConfirm {
+		return nil
+	}
+	amount := model.NewMoney(sign*spending.BaseAmount.Minor, spending.BaseAmount.Currency)
+	return UpsertSummary(db, spending.UserSessionID, categoryIDOf(spending), spending.Category,
+		amount, spending.Datetime)
+}
+
+// recategorizeSpending moves a spending into another category, moving a confirmed
+// spending's amount between the summaries as well
+func recategorizeSpending(tx *gorm.DB, spending *model.Spending, category *model.Category) error {
+	previous := *spending
+
+	spending.Category = category.Name
+	spending.CategoryID = &category.ID
+	if err := tx.Model(spending).Updates(map[string]interface{}{
+		"category":    category.Name,
+		"category_id": category.ID,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := applySpendingSummary(tx, &previous, -1); err != nil {
+		return err
+	}
+	return applySpendingSummary(tx, spending, 1)
+}
+
+func categoryIDOf(spending *model.Spending) uuid.UUID {
+	if spending.CategoryID == nil {
+		return uuid.Nil
+	}
+	return *spending.CategoryID
+}
+
+// GetCategories returns the active default categories merged with the user's own. A
+// default is left out when the user has an own category of the same name.
+func (s *spendingService) GetCategories(
+	c *fiber.Ctx, params *validation.QuerySpendingCategory,
+) ([]model.Category, int64, error) {
 	var categories []model.Category
 	var totalResults int64
 
@@ -129,7 +621,10 @@ func (s *spendingService) GetCategories(c *fiber.Ctx, params *validation.QueryUs
 		return nil, 0, err
 	}
 
-	query := s.DB.WithContext(c.Context()).Model(&model.Category{}).Order("created_at asc")
+	query, err := s.userCategories(c, params.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	if search := params.Search; search != "" {
 		query = query.Where("name LIKE ?", "%"+search+"%")
@@ -150,6 +645,45 @@ func (s *spendingService) GetCategories(c *fiber.Ctx, params *validation.QueryUs
 	return categories, totalResults, nil
 }
 
+// GetCategoryTree returns the categories of GetCategories nested under their parents
+func (s *spendingService) GetCategoryTree(
+	c *fiber.Ctx, params *validation.QuerySpendingCategory,
+) ([]response.CategoryNode, error) {
+	var categories []model.Category
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	query, err := s.userCategories(c, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := query.Find(&categories).Error; err != nil {
+		s.Log.Errorf("Failed to get categories: %+v", err)
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+// userCategories queries the active categories a user sees
+func (s *spendingService) userCategories(c *fiber.Ctx, userID string) (*gorm.DB, error) {
+	owner, err := s.SessionService.GetUserSessionIDs(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.DB.WithContext(c.Context()).Model(&model.Category{}).
+		Scopes(categoriesVisibleTo(owner)).
+		Where("is_active = ?", true).
+		Where(`(owner_id IS NOT NULL OR NOT EXISTS (
+			SELECT 1 FROM categories own
+			WHERE own.owner_id IN ? AND LOWER(own.name) = LOWER(categories.name)))`, owner).
+		Order("created_at asc"), nil
+}
+
 // getWeekRange returns the Monday-Sunday range for the week of t
 func getWeekRange(t time.Time) (time.Time, time.Time) {
 	weekday := int(t.Weekday())
@@ -185,79 +719,105 @@ func getDailyRange(t time.Time) (time.Time, time.Time) {
 	return start, end
 }
 
-// UpsertSummary upserts daily, weekly, monthly, and yearly summaries
-func UpsertSummary(db *gorm.DB, userSessionID uuid.UUID, categoryID uuid.UUID, category string, amount int64) error {
-	now := time.Now()
-	// Daily
-	dayStart, dayEnd := getDailyRange(now)
-	if err := upsertSpendingSummary(db, userSessionID, categoryID, category, amount, dayStart, dayEnd, "daily"); err != nil {
-		return err
-	}
-	// Weekly
-	weekStart, weekEnd := getWeekRange(now)
-	if err := upsertSpendingSummary(db, userSessionID, categoryID, category, amount, weekStart, weekEnd, "weekly"); err != nil {
+// summaryPeriods lists the period types kept in category_spending_summaries with their range helper
+var summaryPeriods = []struct {
+	Type  string
+	Range func(time.Time) (time.Time, time.Time)
+}{
+	{"daily", getDailyRange},
+	{"weekly", getWeekRange},
+	{"monthly", getMonthRange},
+	{"yearly", getYearRange},
+}
+
+// UpsertSummary adds amount to the daily, weekly, monthly, and yearly summaries containing at,
+// with period boundaries in the timezone of the session's owner. Amounts in another currency
+// than the owner's base currency, e.g. queued before the base currency changed, are converted
+// at the rate of at. A negative amount takes a spending back out of its periods. All four rows
+// are written by a single INSERT ... ON CONFLICT so concurrent spendings never create duplicate periods.
+func UpsertSummary(db *gorm.DB, userSessionID uuid.UUID, categoryID uuid.UUID, category string, amount model.Money, at time.Time) error {
+	prefs, err := loadSessionPreferences(db, userSessionID)
+	if err != nil {
 		return err
 	}
-	// Monthly
-	monthStart, monthEnd := getMonthRange(now)
-	if err := upsertSpendingSummary(db, userSessionID, categoryID, category, amount, monthStart, monthEnd, "monthly"); err != nil {
+
+	if amount, _, err = prefs.toBase(db, amount, at); err != nil {
 		return err
 	}
-	// Yearly
-	yearStart, yearEnd := getYearRange(now)
-	return upsertSpendingSummary(db, userSessionID, categoryID, category, amount, yearStart, yearEnd, "yearly")
-}
-
-// upsertSpendingSummary inserts or updates a summary for the given period
-func upsertSpendingSummary(db *gorm.DB, userSessionID uuid.UUID, categoryID uuid.UUID, category string, amount int64, periodStart, periodEnd time.Time, periodType string) error {
-	var summary model.CategorySpendingSummary
-	err := db.Where("user_session_id = ? AND category_id = ? AND period_start = ? AND period_end = ? AND period_type = ?",
-		userSessionID, categoryID, periodStart, periodEnd, periodType).
-		First(&summary).Error
+	at = at.In(prefs.Location)
 
-	if err == gorm.ErrRecordNotFound {
-		// Insert new
-		summary = model.CategorySpendingSummary{
+	summaries := make([]model.CategorySpendingSummary, 0, len(summaryPeriods))
+	for _, period := range summaryPeriods {
+		periodStart, periodEnd := period.Range(at)
+		summaries = append(summaries, model.CategorySpendingSummary{
 			UserSessionID: userSessionID,
 			CategoryID:    categoryID,
 			Category:      category,
 			TotalAmount:   amount,
 			PeriodStart:   periodStart,
 			PeriodEnd:     periodEnd,
-			PeriodType:    periodType,
-		}
-		return db.Create(&summary).Error
-	} else if err != nil {
-		return err
+			PeriodType:    period.Type,
+		})
 	}
 
-	// Update existing
-	return db.Model(&summary).Update("total_amount", gorm.Expr("total_amount + ?", amount)).Error
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "user_session_id"}, {Name: "category_id"}, {Name: "period_type"}, {Name: "period_start"},
+		},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"total_amount_minor": gorm.Expr("category_spending_summaries.total_amount_minor + excluded.total_amount_minor"),
+			"updated_at":         gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&summaries).Error
 }
 
-// GetSummaryTotal returns the total spending for a user session and period type
+// GetSummaryTotal returns the total spending across a user's sessions for a period type,
+// in the user's base currency
 func (s *spendingService) GetSummaryTotal(c *fiber.Ctx, params *validation.QuerySpendingSummary) (response.TotalSummarySpending, error) {
 	if err := s.Validate.Struct(params); err != nil {
 		return response.TotalSummarySpending{}, err
 	}
 
+	owner, err := s.SessionService.GetUserSessionIDs(c, params.UserID)
+	if err != nil {
+		return response.TotalSummarySpending{}, err
+	}
+
+	loc, prefs, err := requestPreferences(s.DB.WithContext(c.Context()), params.Timezone, owner)
+	if err != nil {
+		return response.TotalSummarySpending{}, err
+	}
+
+	summaryRange, err := parseSummaryRange(params, loc)
+	if err != nil {
+		return response.TotalSummarySpending{}, err
+	}
+
 	if params.PeriodType == "" || params.PeriodType == "all" {
 		params.PeriodType = "yearly" // Default to yearly if not specified
 	}
 
 	var totalSpending int64
-	result := s.DB.WithContext(c.Context()).
-		Model(&model.CategorySpendingSummary{}).
-		Select("SUM(total_amount)").
-		Where("user_session_id = ? AND period_type = ?", params.UserSessionID, params.PeriodType).
-		Scan(&totalSpending)
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		if summaryRange != nil {
+			return tx.Table("(?) as summary", summaryRange.groupedByCategory(tx, owner, prefs.Location)).
+				Select("COALESCE(SUM(total_amount_minor), 0)").
+				Scan(&totalSpending).Error
+		}
 
-	if result.Error != nil {
-		s.Log.Errorf("Failed to get total spending: %+v", result.Error)
-		return response.TotalSummarySpending{}, result.Error
+		return tx.Model(&model.CategorySpendingSummary{}).
+			Scopes(OwnedBy(owner...)).
+			Select("COALESCE(SUM(total_amount_minor), 0)").
+			Where("period_type = ?", params.PeriodType).
+			Scan(&totalSpending).Error
+	})
+
+	if err != nil {
+		s.Log.Errorf("Failed to get total spending: %+v", err)
+		return response.TotalSummarySpending{}, err
 	}
 
-	return response.TotalSummarySpending{Total: totalSpending}, nil
+	return response.TotalSummarySpending{Total: model.NewMoney(totalSpending, prefs.BaseCurrency)}, nil
 }
 
 func (s *spendingService) GetSummarySpending(c *fiber.Ctx, params *validation.QuerySpendingSummary) ([]model.CategorySpendingSummary, int64, error) {
@@ -268,43 +828,78 @@ func (s *spendingService) GetSummarySpending(c *fiber.Ctx, params *validation.Qu
 		return nil, 0, err
 	}
 
-	subQuery := s.DB.
-		WithContext(c.Context()).
-		Model(&model.CategorySpendingSummary{}).
-		Select(
-			"category_id",
-			"category",
-			"SUM(total_amount) AS total_amount",
-			"MIN(period_start) AS period_start",
-			"MAX(period_end) AS period_end",
-			"period_type",
-		).
-		Where("period_type = ?", "daily").
-		Where("user_session_id = ?", params.UserSessionID).
-		Group("category_id, category, period_type")
-
-	// Wrap the subquery into a query to count result
-	countResult := s.DB.Table("(?) as summary", subQuery).Count(&totalResults)
-	if countResult.Error != nil {
-		s.Log.Errorf("Failed to count grouped summaries: %+v", countResult.Error)
-		return nil, 0, countResult.Error
-	}
-
-	// Run the actual select
-	findResult := s.DB.Raw(`
-		SELECT 
-			category_id, category, SUM(total_amount) AS total_amount,
-			MIN(period_start) AS period_start, MAX(period_end) AS period_end,
-			period_type
-		FROM category_spending_summaries
-		WHERE period_type = ?
-		GROUP BY category_id, category, period_type
-		ORDER BY total_amount DESC
-	`, "daily").Scan(&summaries)
-
-	if findResult.Error != nil {
-		s.Log.Errorf("Failed to fetch grouped summaries: %+v", findResult.Error)
-		return nil, 0, findResult.Error
+	owner, err := s.SessionService.GetUserSessionIDs(c, params.UserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	loc, prefs, err := requestPreferences(s.DB.WithContext(c.Context()), params.Timezone, owner)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaryRange, err := parseSummaryRange(params, loc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		grouped := func() *gorm.DB {
+			if summaryRange != nil {
+				query := summaryRange.groupedByCategory(tx, owner, prefs.Location)
+				if params.Rollup {
+					return rolledUp(tx, query)
+				}
+				return query
+			}
+			query := tx.Model(&model.CategorySpendingSummary{}).
+				Scopes(OwnedBy(owner...)).
+				Select(
+					"category_id",
+					"category",
+					"total_amount_currency",
+					"SUM(total_amount_minor) AS total_amount_minor",
+					"MIN(period_start) AS period_start",
+					"MAX(period_end) AS period_end",
+					"period_type",
+				).
+				Where("period_type = ?", "daily").
+				Group("category_id, category, total_amount_currency, period_type")
+			if params.Rollup {
+				return rolledUp(tx, query,
+					"MIN(grouped.period_start) AS period_start",
+					"MAX(grouped.period_end) AS period_end",
+					"MIN(grouped.period_type) AS period_type",
+				)
+			}
+			return query
+		}
+
+		// Wrap the grouped query into a query to count result
+		if err := tx.Table("(?) as summary", grouped()).Count(&totalResults).Error; err != nil {
+			s.Log.Errorf("Failed to count grouped summaries: %+v", err)
+			return err
+		}
+
+		// Run the actual select
+		if err := grouped().Order("total_amount_minor DESC").Scan(&summaries).Error; err != nil {
+			s.Log.Errorf("Failed to fetch grouped summaries: %+v", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if summaryRange != nil {
+		for i := range summaries {
+			summaries[i].PeriodStart = summaryRange.Start
+			summaries[i].PeriodEnd = summaryRange.End.Add(-time.Nanosecond)
+			summaries[i].PeriodType = summaryRange.Type
+		}
 	}
 
 	return summaries, totalResults, nil
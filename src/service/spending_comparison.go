@@ -0,0 +1,193 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// summaryPeriodRange returns the range helper of a summary period type
+func summaryPeriodRange(periodType string) func(time.Time) (time.Time, time.Time) {
+	for _, period := range summaryPeriods {
+		if period.Type == periodType {
+			return period.Range
+		}
+	}
+	return nil
+}
+
+// GetComparison compares each category's spending in the week, month or year containing
+// params.Date (today by default) with the period right before it. Periods are computed
+// in the request timezone and totalled like a custom summary range, in the base currency.
+// With params.Rollup subcategories count toward their top-level category.
+func (s *spendingService) GetComparison(
+	c *fiber.Ctx, params *validation.QuerySpendingComparison,
+) (*response.SpendingComparison, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.SessionService.GetUserSessionIDs(c, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, prefs, err := requestPreferences(s.DB.WithContext(c.Context()), params.Timezone, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now().In(loc)
+	if params.Date != "" {
+		parsed, _, err := validation.ParsePeriod(params.Date, loc)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid date, use YYYY-MM-DD or RFC 3339")
+		}
+		date = parsed.In(loc)
+	}
+
+	periodRange := summaryPeriodRange(params.PeriodType)
+	currentStart, currentEnd := periodRange(date)
+	previousStart, previousEnd := periodRange(currentStart.Add(-time.Nanosecond))
+
+	periods := []struct {
+		Current bool
+		Range   summaryRange
+	}{
+		{true, summaryRange{Start: currentStart, End: currentEnd.Add(time.Nanosecond)}},
+		{false, summaryRange{Start: previousStart, End: previousEnd.Add(time.Nanosecond)}},
+	}
+
+	type categoryTotal struct {
+		CategoryID       uuid.UUID
+		Category         string
+		TotalAmountMinor int64
+		IsTopLevel       bool
+		Current          bool
+	}
+	var rows []categoryTotal
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		for _, period := range periods {
+			var totals []categoryTotal
+			grouped := period.Range.groupedByCategory(tx, owner, prefs.Location)
+			if params.Rollup {
+				grouped = rolledUp(tx, grouped)
+			}
+			if err := grouped.Scan(&totals).Error; err != nil {
+				return err
+			}
+			for _, total := range totals {
+				total.Current = period.Current
+				rows = append(rows, total)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		s.Log.Errorf("Failed to get spending comparison: %+v", err)
+		return nil, err
+	}
+
+	comparison := &response.SpendingComparison{
+		PeriodType:    params.PeriodType,
+		CurrentStart:  currentStart,
+		CurrentEnd:    currentEnd,
+		PreviousStart: previousStart,
+		PreviousEnd:   previousEnd,
+		CurrentTotal:  model.NewMoney(0, prefs.BaseCurrency),
+		PreviousTotal: model.NewMoney(0, prefs.BaseCurrency),
+		Categories:    []response.CategoryComparison{},
+	}
+
+	byCategory := make(map[uuid.UUID]*response.CategoryComparison)
+	for _, row := range rows {
+		category, ok := byCategory[row.CategoryID]
+		if !ok {
+			category = &response.CategoryComparison{
+				CategoryID:    row.CategoryID,
+				Category:      row.Category,
+				CurrentTotal:  model.NewMoney(0, prefs.BaseCurrency),
+				PreviousTotal: model.NewMoney(0, prefs.BaseCurrency),
+			}
+			byCategory[row.CategoryID] = category
+		}
+
+		// Rolled up subcategories are already in their top-level category's total
+		counted := !params.Rollup || row.IsTopLevel
+		if row.Current {
+			category.CurrentTotal.Minor += row.TotalAmountMinor
+			if counted {
+				comparison.CurrentTotal.Minor += row.TotalAmountMinor
+			}
+		} else {
+			category.PreviousTotal.Minor += row.TotalAmountMinor
+			if counted {
+				comparison.PreviousTotal.Minor += row.TotalAmountMinor
+			}
+		}
+	}
+
+	for _, category := range byCategory {
+		current, previous := category.CurrentTotal.Minor, category.PreviousTotal.Minor
+
+		// Categories whose spendings were all deleted keep a zero row, they say nothing
+		if current == 0 && previous == 0 {
+			continue
+		}
+
+		category.Change = model.NewMoney(current-previous, prefs.BaseCurrency)
+		category.ChangePercent = changePercent(current, previous)
+		category.Trend = trendOf(current, previous)
+		if comparison.CurrentTotal.Minor > 0 {
+			category.Share = float64(current) / float64(comparison.CurrentTotal.Minor) * 100
+		}
+		comparison.Categories = append(comparison.Categories, *category)
+	}
+
+	comparison.Change = model.NewMoney(comparison.CurrentTotal.Minor-comparison.PreviousTotal.Minor, prefs.BaseCurrency)
+	comparison.ChangePercent = changePercent(comparison.CurrentTotal.Minor, comparison.PreviousTotal.Minor)
+
+	sort.Slice(comparison.Categories, func(i, j int) bool {
+		a, b := comparison.Categories[i], comparison.Categories[j]
+		if a.CurrentTotal.Minor != b.CurrentTotal.Minor {
+			return a.CurrentTotal.Minor > b.CurrentTotal.Minor
+		}
+		if a.PreviousTotal.Minor != b.PreviousTotal.Minor {
+			return a.PreviousTotal.Minor > b.PreviousTotal.Minor
+		}
+		return a.Category < b.Category
+	})
+
+	return comparison, nil
+}
+
+// changePercent returns the change from previous to current in percent,
+// or nil when there was nothing to compare against
+func changePercent(current, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	percent := float64(current-previous) / float64(previous) * 100
+	return &percent
+}
+
+// trendOf labels a category as new, up, down or flat compared to the previous period
+func trendOf(current, previous int64) string {
+	switch {
+	case previous == 0:
+		return "new"
+	case current > previous:
+		return "up"
+	case current < previous:
+		return "down"
+	default:
+		return "flat"
+	}
+}
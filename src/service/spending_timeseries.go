@@ -0,0 +1,142 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxTimeseriesPoints keeps a single chart request from generating an unbounded series
+const maxTimeseriesPoints = 400
+
+// timeseriesBuckets maps a bucket to the range helper of its first bucket and its step
+var timeseriesBuckets = map[string]struct {
+	Range func(time.Time) (time.Time, time.Time)
+	Next  func(time.Time) time.Time
+}{
+	"day":   {getDailyRange, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	"week":  {getWeekRange, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+	"month": {getMonthRange, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+}
+
+// GetTimeseries returns the confirmed spending per day, week or month of a range.
+// Buckets without spendings are returned with a zero total so charts have no gaps.
+// Totals are in the user's base currency. With params.Rollup a category filter includes
+// the category's subcategories.
+// Bucket boundaries are computed in Go in the request timezone and passed to the
+// query, so they do not depend on the database session timezone.
+func (s *spendingService) GetTimeseries(
+	c *fiber.Ctx, params *validation.QuerySpendingTimeseries,
+) (*response.SpendingTimeseries, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.SessionService.GetUserSessionIDs(c, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, prefs, err := requestPreferences(s.DB.WithContext(c.Context()), params.Timezone, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryRange, err := newSummaryRange(params.PeriodStart, params.PeriodEnd, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := timeseriesBuckets[params.Bucket]
+	first, _ := bucket.Range(summaryRange.Start.In(loc))
+
+	var starts []time.Time
+	for start := first; start.Before(summaryRange.End); start = bucket.Next(start) {
+		if len(starts) == maxTimeseriesPoints {
+			return nil, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("The range has more than %d %s buckets, use a larger bucket", maxTimeseriesPoints, params.Bucket))
+		}
+		starts = append(starts, start)
+	}
+
+	var categoryID *uuid.UUID
+	if params.CategoryID != "" {
+		id := uuid.MustParse(params.CategoryID)
+		categoryID = &id
+	}
+
+	var rows []struct {
+		PeriodStart time.Time
+		TotalAmount int64
+	}
+	err = withOwner(s.DB.WithContext(c.Context()), owner, func(tx *gorm.DB) error {
+		// The first and last bucket may reach outside the range, only spendings inside it count
+		spendingFilter := "s.is_confirm AND s.user_session_id IN @owner AND s.datetime >= @start AND s.datetime < @end"
+		if categoryID != nil && params.Rollup {
+			spendingFilter += " AND s.category_id IN (SELECT id FROM (" + categorySubtreeSQL + ") AS subtree)"
+		} else if categoryID != nil {
+			spendingFilter += " AND s.category_id = @category"
+		}
+
+		return tx.Raw(`
+			SELECT b.period_start, COALESCE(SUM(s.base_amount_minor), 0)::bigint AS total_amount
+			FROM unnest(@starts::timestamptz[], @ends::timestamptz[]) AS b(period_start, period_end)
+			LEFT JOIN spendings s
+				ON s.datetime >= b.period_start AND s.datetime < b.period_end
+				AND `+spendingFilter+`
+			GROUP BY b.period_start
+			ORDER BY b.period_start`,
+			map[string]interface{}{
+				"owner":    owner,
+				"start":    summaryRange.Start,
+				"end":      summaryRange.End,
+				"starts":   timestampArray(starts, func(t time.Time) time.Time { return t }),
+				"ends":     timestampArray(starts, bucket.Next),
+				"category": categoryID,
+			},
+		).Scan(&rows).Error
+	})
+
+	if err != nil {
+		s.Log.Errorf("Failed to get spending timeseries: %+v", err)
+		return nil, err
+	}
+
+	timeseries := &response.SpendingTimeseries{
+		Bucket:      params.Bucket,
+		PeriodStart: summaryRange.Start,
+		PeriodEnd:   summaryRange.End.Add(-time.Nanosecond),
+		CategoryID:  categoryID,
+		Total:       model.NewMoney(0, prefs.BaseCurrency),
+		Points:      make([]response.TimeseriesPoint, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		start := row.PeriodStart.In(loc)
+		timeseries.Total.Minor += row.TotalAmount
+		timeseries.Points = append(timeseries.Points, response.TimeseriesPoint{
+			PeriodStart: start,
+			PeriodEnd:   bucket.Next(start).Add(-time.Nanosecond),
+			TotalAmount: model.NewMoney(row.TotalAmount, prefs.BaseCurrency),
+		})
+	}
+
+	return timeseries, nil
+}
+
+// timestampArray formats times as a Postgres array literal. GORM expands slice
+// arguments into value lists, so arrays are passed as text and cast in the query.
+func timestampArray(times []time.Time, fn func(time.Time) time.Time) string {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = fn(t).Format(time.RFC3339Nano)
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
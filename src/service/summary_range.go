@@ -0,0 +1,116 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/validation"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// summaryRange is the half-open interval [Start, End) of a custom summary, or of the
+// current period of Type
+type summaryRange struct {
+	Start time.Time
+	End   time.Time
+	Type  string
+}
+
+// parseSummaryRange reads period_start and period_end, both bounds are inclusive and
+// a date covers the whole day in loc, so 2025-07-01 to 2025-07-31 is July. Without them
+// a daily, weekly, monthly or yearly period_type is the current period in loc. It
+// returns nil when no range was requested.
+func parseSummaryRange(params *validation.QuerySpendingSummary, loc *time.Location) (*summaryRange, error) {
+	if params.PeriodStart == "" && params.PeriodEnd == "" {
+		periodRange := summaryPeriodRange(params.PeriodType)
+		if periodRange == nil {
+			return nil, nil
+		}
+
+		start, end := periodRange(time.Now().In(loc))
+		return &summaryRange{Start: start, End: end.Add(time.Nanosecond), Type: params.PeriodType}, nil
+	}
+
+	if params.PeriodType != "" && params.PeriodType != "custom" {
+		return nil, fiber.NewError(fiber.StatusBadRequest,
+			"period_type must be custom or empty when period_start and period_end are set")
+	}
+
+	return newSummaryRange(params.PeriodStart, params.PeriodEnd, loc)
+}
+
+// newSummaryRange parses inclusive period bounds into a half-open range
+func newSummaryRange(periodStart, periodEnd string, loc *time.Location) (*summaryRange, error) {
+	start, _, err := validation.ParsePeriod(periodStart, loc)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid period_start, use YYYY-MM-DD or RFC 3339")
+	}
+
+	end, dateOnly, err := validation.ParsePeriod(periodEnd, loc)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid period_end, use YYYY-MM-DD or RFC 3339")
+	}
+
+	if dateOnly {
+		end = end.AddDate(0, 0, 1)
+	} else {
+		end = end.Add(time.Nanosecond)
+	}
+
+	if !end.After(start) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "period_end must not be before period_start")
+	}
+
+	return &summaryRange{Start: start, End: end, Type: "custom"}, nil
+}
+
+// alignedToDays reports whether the range starts and ends at midnight in loc, so
+// daily summary rows kept in loc cover it exactly
+func (r *summaryRange) alignedToDays(loc *time.Location) bool {
+	dayStart, _ := getDailyRange(r.Start.In(loc))
+	dayEnd, _ := getDailyRange(r.End.In(loc))
+	return dayStart.Equal(r.Start) && dayEnd.Equal(r.End)
+}
+
+// groupedByCategory totals the range per category. Ranges of whole days in summaryLoc,
+// the timezone of the owner's summaries, are read from the daily summaries, other
+// ranges from the base amounts of the confirmed spendings.
+func (r *summaryRange) groupedByCategory(tx *gorm.DB, owner []uuid.UUID, summaryLoc *time.Location) *gorm.DB {
+	if r.alignedToDays(summaryLoc) {
+		return tx.Model(&model.CategorySpendingSummary{}).
+			Scopes(OwnedBy(owner...)).
+			Select("category_id", "category", "total_amount_currency", "SUM(total_amount_minor) AS total_amount_minor").
+			Where("period_type = ? AND period_start >= ? AND period_start < ?", "daily", r.Start, r.End).
+			Group("category_id, category, total_amount_currency")
+	}
+
+	return tx.Model(&model.Spending{}).
+		Scopes(OwnedBy(owner...)).
+		Select(`COALESCE(category_id, ?) AS category_id, category,
+			base_amount_currency AS total_amount_currency, SUM(base_amount_minor) AS total_amount_minor`, uuid.Nil).
+		Where("is_confirm = ? AND datetime >= ? AND datetime < ?", true, r.Start, r.End).
+		Group("category_id, category, base_amount_currency")
+}
+
+// rolledUp regroups the per category totals of grouped under each category and every
+// category above it, so a category's total includes its subcategories. extra aggregates
+// further columns of grouped. A spending counts in each of its ancestors, is_top_level
+// marks the rows that add up to the overall total.
+func rolledUp(tx *gorm.DB, grouped *gorm.DB, extra ...string) *gorm.DB {
+	columns := append([]string{
+		"COALESCE(ancestors.ancestor_id, grouped.category_id) AS category_id",
+		"COALESCE(ancestors.ancestor_name, grouped.category) AS category",
+		"COALESCE(ancestors.ancestor_is_root, true) AS is_top_level",
+		"grouped.total_amount_currency",
+		"SUM(grouped.total_amount_minor) AS total_amount_minor",
+	}, extra...)
+
+	return tx.Table("(?) AS grouped", grouped).
+		Joins("LEFT JOIN category_ancestors ancestors ON ancestors.id = grouped.category_id").
+		Select(strings.Join(columns, ", ")).
+		Group("COALESCE(ancestors.ancestor_id, grouped.category_id), COALESCE(ancestors.ancestor_name, grouped.category), " +
+			"COALESCE(ancestors.ancestor_is_root, true), grouped.total_amount_currency")
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	SummaryCreate = "create"
+	SummaryUpdate = "update"
+	SummaryDelete = "delete"
+)
+
+type SummaryService interface {
+	RebuildSummaries(ctx context.Context, req *validation.RebuildSummaries) (*response.RebuildSummaries, error)
+}
+
+type summaryService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewSummaryService(db *gorm.DB, validate *validator.Validate) SummaryService {
+	return &summaryService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+type summaryKey struct {
+	UserSessionID uuid.UUID
+	CategoryID    uuid.UUID
+	PeriodType    string
+	PeriodStart   int64
+}
+
+type summaryTarget struct {
+	Category    string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	TotalAmount model.Money
+}
+
+// RebuildSummaries recomputes the daily, weekly, monthly and yearly summary rows
+// from the confirmed spendings of one user, or of everyone when no user is given.
+// It takes the summary table lock, so concurrent updates wait until it is done.
+// Summary events still waiting in the outbox are left out, the worker adds them later.
+func (s *summaryService) RebuildSummaries(
+	ctx context.Context, req *validation.RebuildSummaries,
+) (*response.RebuildSummaries, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	var report *response.RebuildSummaries
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSummaries(tx); err != nil {
+			return err
+		}
+
+		return withoutOwner(tx, func(tx *gorm.DB) error {
+			owner, err := s.ownerScope(tx, req.UserID)
+			if err != nil {
+				return err
+			}
+
+			report, err = rebuildSummaries(tx, owner, req.DryRun)
+			return err
+		})
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+
+	if err != nil {
+		s.Log.Errorf("Failed to rebuild summaries: %+v", err)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// lockSummaries keeps summary updates out until the transaction ends. In a repeatable read
+// transaction it has to be the first statement: the snapshot is taken by the first query,
+// and one taken before the lock misses the updates committed while waiting for it.
+func lockSummaries(tx *gorm.DB) error {
+	return tx.Exec("LOCK TABLE category_spending_summaries IN SHARE ROW EXCLUSIVE MODE").Error
+}
+
+// rebuildUserSummaries rebuilds the summaries of the user and their sessions as part
+// of tx, so they move with a change of the user's timezone or sessions. tx must hold
+// the lockSummaries lock.
+func rebuildUserSummaries(tx *gorm.DB, userID uuid.UUID) error {
+	return withoutOwner(tx, func(tx *gorm.DB) error {
+		owner, err := ownerSessionIDs(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = rebuildSummaries(tx, owner, false)
+		return err
+	})
+}
+
+// rebuildSummaries recomputes the summaries of the given sessions, or of everyone when
+// owner is nil, inside a transaction that holds the lockSummaries lock and bypasses the
+// owner policies
+func rebuildSummaries(tx *gorm.DB, owner []uuid.UUID, dryRun bool) (*response.RebuildSummaries, error) {
+	report := &response.RebuildSummaries{DryRun: dryRun, Changes: []response.SummaryChange{}}
+
+	targets, spendings, err := targetSummaries(tx, owner)
+	if err != nil {
+		return nil, err
+	}
+	report.Spendings = spendings
+
+	var existing []model.CategorySpendingSummary
+	if err := tx.Scopes(summaryOwnedBy(owner)).
+		Where("period_type IN ?", summaryPeriodTypes()).
+		Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	var creates []model.CategorySpendingSummary
+	var deletes []uuid.UUID
+
+	for _, summary := range existing {
+		key := summaryKey{summary.UserSessionID, summary.CategoryID, summary.PeriodType, summary.PeriodStart.UnixNano()}
+		target, found := targets[key]
+		delete(targets, key)
+
+		switch {
+		case !found || target.TotalAmount.Minor == 0:
+			deletes = append(deletes, summary.ID)
+			report.Changes = append(report.Changes,
+				summaryChange(SummaryDelete, &summary, summary.TotalAmount, model.NewMoney(0, summary.TotalAmount.Currency)))
+		case target.TotalAmount != summary.TotalAmount:
+			report.Changes = append(report.Changes,
+				summaryChange(SummaryUpdate, &summary, summary.TotalAmount, target.TotalAmount))
+			if !dryRun {
+				if err := tx.Model(&model.CategorySpendingSummary{}).
+					Where("id = ?", summary.ID).
+					Updates(map[string]interface{}{
+						"total_amount_minor":    target.TotalAmount.Minor,
+						"total_amount_currency": target.TotalAmount.Currency,
+					}).Error; err != nil {
+					return nil, err
+				}
+			}
+		default:
+			report.Unchanged++
+		}
+	}
+
+	for key, target := range targets {
+		if target.TotalAmount.Minor == 0 {
+			continue
+		}
+		summary := model.CategorySpendingSummary{
+			UserSessionID: key.UserSessionID,
+			CategoryID:    key.CategoryID,
+			Category:      target.Category,
+			TotalAmount:   target.TotalAmount,
+			PeriodStart:   target.PeriodStart,
+			PeriodEnd:     target.PeriodEnd,
+			PeriodType:    key.PeriodType,
+		}
+		creates = append(creates, summary)
+		report.Changes = append(report.Changes,
+			summaryChange(SummaryCreate, &summary, model.NewMoney(0, target.TotalAmount.Currency), target.TotalAmount))
+	}
+
+	sortSummaryChanges(report.Changes)
+	for _, change := range report.Changes {
+		switch change.Action {
+		case SummaryCreate:
+			report.Created++
+		case SummaryUpdate:
+			report.Updated++
+		case SummaryDelete:
+			report.Deleted++
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if len(deletes) > 0 {
+		if err := tx.Delete(&model.CategorySpendingSummary{}, "id IN ?", deletes).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if len(creates) > 0 {
+		if err := tx.CreateInBatches(&creates, 500).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// ownerScope returns the session ids of the user, or nil to rebuild everyone
+func (s *summaryService) ownerScope(tx *gorm.DB, userID string) ([]uuid.UUID, error) {
+	if userID == "" {
+		return nil, nil
+	}
+
+	return ownerSessionIDs(tx, uuid.MustParse(userID))
+}
+
+func summaryOwnedBy(owner []uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if owner == nil {
+			return db
+		}
+		return OwnedBy(owner...)(db)
+	}
+}
+
+// targetSummaries adds up the confirmed spendings per summary period, minus the
+// amounts of spending_created events the worker has not applied yet
+func targetSummaries(tx *gorm.DB, owner []uuid.UUID) (map[summaryKey]*summaryTarget, int, error) {
+	targets := make(map[summaryKey]*summaryTarget)
+	preferences := newSessionPreferenceCache(tx)
+	count := 0
+
+	var spendings []model.Spending
+	result := tx.Scopes(summaryOwnedBy(owner)).
+		Where("is_confirm = ?", true).
+		FindInBatches(&spendings, 1000, func(_ *gorm.DB, _ int) error {
+			for i := range spendings {
+				prefs, err := preferences.of(spendings[i].UserSessionID)
+				if err != nil {
+					return err
+				}
+				addSummaryTarget(targets, spendings[i].UserSessionID, categoryIDOf(&spendings[i]),
+					spendings[i].Category, spendings[i].BaseAmount, spendings[i].Datetime.In(prefs.Location))
+			}
+			count += len(spendings)
+			return nil
+		})
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	var events []model.OutboxEvent
+	if err := tx.Where("type = ? AND status = ?", EventSpendingCreated, OutboxPending).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	inScope := make(map[uuid.UUID]bool, len(owner))
+	for _, id := range owner {
+		inScope[id] = true
+	}
+
+	for _, event := range events {
+		var payload SpendingCreatedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			utils.Log.Errorf("Skipping unreadable outbox event %s: %+v", event.ID, err)
+			continue
+		}
+		if owner != nil && !inScope[payload.UserSessionID] {
+			continue
+		}
+		prefs, err := preferences.of(payload.UserSessionID)
+		if err != nil {
+			return nil, 0, err
+		}
+		// The worker converts events queued before a base currency change the same way
+		amount, _, err := prefs.toBase(tx, payload.Amount, payload.Datetime)
+		if err != nil {
+			return nil, 0, err
+		}
+		pending := model.NewMoney(-amount.Minor, amount.Currency)
+		addSummaryTarget(targets, payload.UserSessionID, payload.CategoryID, payload.Category,
+			pending, payload.Datetime.In(prefs.Location))
+	}
+
+	return targets, count, nil
+}
+
+func addSummaryTarget(
+	targets map[summaryKey]*summaryTarget, userSessionID, categoryID uuid.UUID, category string,
+	amount model.Money, at time.Time,
+) {
+	for _, period := range summaryPeriods {
+		periodStart, periodEnd := period.Range(at)
+		key := summaryKey{userSessionID, categoryID, period.Type, periodStart.UnixNano()}
+
+		target, found := targets[key]
+		if !found {
+			target = &summaryTarget{
+				Category:    category,
+				PeriodStart: periodStart,
+				PeriodEnd:   periodEnd,
+				TotalAmount: model.NewMoney(0, amount.Currency),
+			}
+			targets[key] = target
+		}
+		target.TotalAmount.Minor += amount.Minor
+	}
+}
+
+func summaryPeriodTypes() []string {
+	types := make([]string, 0, len(summaryPeriods))
+	for _, period := range summaryPeriods {
+		types = append(types, period.Type)
+	}
+	return types
+}
+
+func summaryChange(action string, summary *model.CategorySpendingSummary, before, after model.Money) response.SummaryChange {
+	return response.SummaryChange{
+		Action:        action,
+		UserSessionID: summary.UserSessionID,
+		CategoryID:    summary.CategoryID,
+		Category:      summary.Category,
+		PeriodType:    summary.PeriodType,
+		PeriodStart:   summary.PeriodStart,
+		Before:        before,
+		After:         after,
+	}
+}
+
+func sortSummaryChanges(changes []response.SummaryChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.UserSessionID != b.UserSessionID {
+			return a.UserSessionID.String() < b.UserSessionID.String()
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.PeriodType != b.PeriodType {
+			return a.PeriodType < b.PeriodType
+		}
+		return a.PeriodStart.Before(b.PeriodStart)
+	})
+}
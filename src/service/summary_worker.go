@@ -0,0 +1,226 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EventSpendingCreated = "spending_created"
+
+	OutboxPending   = "pending"
+	OutboxProcessed = "processed"
+	OutboxFailed    = "failed"
+)
+
+// SpendingCreatedEvent carries what the summaries need, so they can be applied
+// even after the spending was edited or deleted. Amount is the base amount.
+type SpendingCreatedEvent struct {
+	SpendingID    uuid.UUID   `json:"spending_id"`
+	UserSessionID uuid.UUID   `json:"user_session_id"`
+	CategoryID    uuid.UUID   `json:"category_id"`
+	Category      string      `json:"category"`
+	Amount        model.Money `json:"amount"`
+	Datetime      time.Time   `json:"datetime"`
+}
+
+// SummaryWorker applies the summary updates queued in outbox_events
+type SummaryWorker interface {
+	Start()
+	Notify()
+	ProcessPending(ctx context.Context) (int, error)
+	Shutdown(ctx context.Context) error
+}
+
+type summaryWorker struct {
+	Log          *logrus.Logger
+	DB           *gorm.DB
+	PollInterval time.Duration
+	MaxAttempts  int
+
+	notify   chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewSummaryWorker(db *gorm.DB) SummaryWorker {
+	return &summaryWorker{
+		Log:          utils.Log,
+		DB:           db,
+		PollInterval: config.OutboxPollInterval,
+		MaxAttempts:  config.OutboxMaxAttempts,
+		notify:       make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start polls for pending events until Shutdown is called
+func (w *summaryWorker) Start() {
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				// Drain what is already queued before exiting
+				if _, err := w.ProcessPending(context.Background()); err != nil {
+					w.Log.Errorf("Failed to drain outbox events: %+v", err)
+				}
+				return
+			case <-ticker.C:
+			case <-w.notify:
+			}
+
+			if _, err := w.ProcessPending(context.Background()); err != nil {
+				w.Log.Errorf("Failed to process outbox events: %+v", err)
+			}
+		}
+	}()
+}
+
+// Notify wakes the worker up without waiting for the next poll
+func (w *summaryWorker) Notify() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Shutdown stops polling and waits until the queued events are handled or ctx expires
+func (w *summaryWorker) Shutdown(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ProcessPending handles the events that are due and returns how many were handled
+func (w *summaryWorker) ProcessPending(ctx context.Context) (int, error) {
+	processed := 0
+
+	for ctx.Err() == nil {
+		found, err := w.processNext(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if !found {
+			return processed, nil
+		}
+		processed++
+	}
+
+	return processed, ctx.Err()
+}
+
+// processNext locks one due event, applies it and records the outcome in the same
+// transaction. SKIP LOCKED lets several workers (prefork children) share the queue.
+func (w *summaryWorker) processNext(ctx context.Context) (bool, error) {
+	found := false
+
+	err := withoutOwner(w.DB.WithContext(ctx), func(tx *gorm.DB) error {
+		var event model.OutboxEvent
+
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND available_at <= ?", OutboxPending, time.Now()).
+			Order("available_at asc").
+			Limit(1).
+			Find(&event)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		found = true
+
+		if err := tx.SavePoint("handle").Error; err != nil {
+			return err
+		}
+
+		handleErr := w.handle(tx, &event)
+		if handleErr == nil {
+			now := time.Now()
+			return tx.Model(&event).Updates(map[string]interface{}{
+				"status":       OutboxProcessed,
+				"attempts":     event.Attempts + 1,
+				"last_error":   "",
+				"processed_at": &now,
+			}).Error
+		}
+
+		if err := tx.RollbackTo("handle").Error; err != nil {
+			return err
+		}
+
+		w.Log.Errorf("Failed to handle outbox event %s: %+v", event.ID, handleErr)
+		return tx.Model(&event).Updates(w.failure(&event, handleErr)).Error
+	})
+
+	return found, err
+}
+
+// failure schedules a retry with a growing delay, giving up after MaxAttempts
+func (w *summaryWorker) failure(event *model.OutboxEvent, err error) map[string]interface{} {
+	attempts := event.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":     attempts,
+		"last_error":   err.Error(),
+		"available_at": time.Now().Add(time.Duration(attempts*attempts) * time.Second),
+	}
+	if attempts >= w.MaxAttempts {
+		updates["status"] = OutboxFailed
+	}
+	return updates
+}
+
+func (w *summaryWorker) handle(tx *gorm.DB, event *model.OutboxEvent) error {
+	switch event.Type {
+	case EventSpendingCreated:
+		var payload SpendingCreatedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		return UpsertSummary(tx, payload.UserSessionID, payload.CategoryID, payload.Category,
+			payload.Amount, payload.Datetime)
+	default:
+		return fmt.Errorf("unknown outbox event type %q", event.Type)
+	}
+}
+
+// queueSpendingCreated writes the event that adds a confirmed spending to its summaries
+func queueSpendingCreated(tx *gorm.DB, spending *model.Spending) error {
+	if !spending.IsConfirm {
+		return nil
+	}
+
+	payload, err := json.Marshal(SpendingCreatedEvent{
+		SpendingID:    spending.ID,
+		UserSessionID: spending.UserSessionID,
+		CategoryID:    categoryIDOf(spending),
+		Category:      spending.Category,
+		Amount:        spending.BaseAmount,
+		Datetime:      spending.Datetime,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{Type: EventSpendingCreated, Payload: payload}).Error
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"app/src/config"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoadLocation resolves an IANA timezone name, an empty name is config.AppTimezone
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = config.AppTimezone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid timezone "+name)
+	}
+
+	return loc, nil
+}
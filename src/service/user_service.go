@@ -1,9 +1,11 @@
 package service
 
 import (
+	"app/src/config"
 	"app/src/model"
 	"app/src/utils"
 	"app/src/validation"
+	"database/sql"
 	"errors"
 
 	"github.com/go-playground/validator/v10"
@@ -136,10 +138,15 @@ func (s *userService) UpdateUser(c *fiber.Ctx, req *validation.UpdateUser, id st
 		return nil, err
 	}
 
-	if req.Email == "" && req.Name == "" && req.Password == "" {
+	if req.Email == "" && req.Name == "" && req.Password == "" && req.Timezone == nil && req.BaseCurrency == nil {
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
 	}
 
+	before, err := s.GetUserByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Password != "" {
 		hashedPassword, err := utils.HashPassword(req.Password)
 		if err != nil {
@@ -148,32 +155,88 @@ func (s *userService) UpdateUser(c *fiber.Ctx, req *validation.UpdateUser, id st
 		req.Password = hashedPassword
 	}
 
-	updateBody := &model.User{
-		Name:     req.Name,
-		Password: req.Password,
-		Email:    req.Email,
+	// Updates skips empty fields of a struct, a map also writes the resets of the preferences
+	updateBody := map[string]interface{}{}
+	if req.Name != "" {
+		updateBody["name"] = req.Name
+	}
+	if req.Password != "" {
+		updateBody["password"] = req.Password
+	}
+	if req.Email != "" {
+		updateBody["email"] = req.Email
 	}
 
-	result := s.DB.WithContext(c.Context()).Where("id = ?", id).Updates(updateBody)
-
-	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
-		return nil, fiber.NewError(fiber.StatusConflict, "Email is already in use")
+	timezoneChanged := req.Timezone != nil && *req.Timezone != before.Timezone
+	if req.Timezone != nil {
+		updateBody["timezone"] = *req.Timezone
 	}
 
-	if result.RowsAffected == 0 {
-		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	// Resetting the base currency converts into the default currency, unless it already was
+	baseCurrency := currencyOr(before.BaseCurrency, config.Currency)
+	if req.BaseCurrency != nil {
+		baseCurrency = currencyOr(*req.BaseCurrency, config.Currency)
+		updateBody["base_currency"] = *req.BaseCurrency
 	}
+	currencyChanged := baseCurrency != currencyOr(before.BaseCurrency, config.Currency)
 
-	if result.Error != nil {
-		s.Log.Errorf("Failed to update user: %+v", result.Error)
+	// Spendings are converted and summaries rebuilt with the user update, so a missing rate
+	// or a taken email changes nothing and the response reports the summaries as they are
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if timezoneChanged || currencyChanged {
+			if err := lockSummaries(tx); err != nil {
+				return err
+			}
+		}
+
+		if currencyChanged {
+			if err := s.changeBaseCurrency(tx, before, baseCurrency); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&model.User{}).Where("id = ?", id).Updates(updateBody)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusNotFound, "User not found")
+		}
+
+		// Summary periods follow the user's timezone and currency, so move them to the new ones
+		if timezoneChanged || currencyChanged {
+			return rebuildUserSummaries(tx, before.ID)
+		}
+
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Email is already in use")
 	}
 
-	user, err := s.GetUserByID(c, id)
 	if err != nil {
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			s.Log.Errorf("Failed to update user: %+v", err)
+		}
 		return nil, err
 	}
 
-	return user, result.Error
+	return s.GetUserByID(c, id)
+}
+
+// changeBaseCurrency converts the user's spendings into a new base currency, the
+// caller stores the currency in the same transaction
+func (s *userService) changeBaseCurrency(tx *gorm.DB, user *model.User, currency string) error {
+	prefs, err := loadSessionPreferences(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	prefs.BaseCurrency = currency
+
+	return convertOwnerSpendings(tx, user.ID, prefs)
 }
 
 func (s *userService) UpdatePassOrVerify(c *fiber.Ctx, req *validation.UpdatePassOrVerify, id string) error {
@@ -0,0 +1,18 @@
+package validation
+
+import "encoding/json"
+
+type CreateBudget struct {
+	Category string      `json:"category" validate:"required,max=50" example:"Food"`
+	Period   string      `json:"period" validate:"required,oneof=weekly monthly" example:"monthly"`
+	Amount   json.Number `json:"amount" validate:"required,money" swaggertype:"string" example:"2000000"`
+	Currency string      `json:"currency" validate:"omitempty,iso4217" example:"IDR"`
+}
+
+// UpdateBudget changes the fields that are set, a currency alone re-reads the amount in it
+type UpdateBudget struct {
+	Category string       `json:"category,omitempty" validate:"omitempty,max=50" example:"Food"`
+	Period   *string      `json:"period,omitempty" validate:"omitempty,oneof=weekly monthly" example:"weekly"`
+	Amount   *json.Number `json:"amount,omitempty" validate:"omitempty,money" swaggertype:"string" example:"500000"`
+	Currency *string      `json:"currency,omitempty" validate:"omitempty,iso4217" example:"IDR"`
+}
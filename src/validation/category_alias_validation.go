@@ -0,0 +1,23 @@
+package validation
+
+type CreateCategoryAlias struct {
+	Label      string `json:"label" validate:"required,max=255" example:"Makanan & Minuman"`
+	CategoryID string `json:"category_id" validate:"required,uuid" example:"95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5"`
+}
+
+type QueryCategoryAlias struct {
+	Page       int    `validate:"omitempty,number,max=50"`
+	Limit      int    `validate:"omitempty,number,max=50"`
+	Search     string `validate:"omitempty,max=50"`
+	CategoryID string `validate:"omitempty,uuid"`
+}
+
+type ResolveCategoryLabelReview struct {
+	CategoryID string `json:"category_id" validate:"required,uuid" example:"95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5"`
+}
+
+type QueryCategoryLabelReview struct {
+	Page   int    `validate:"omitempty,number,max=50"`
+	Limit  int    `validate:"omitempty,number,max=50"`
+	Search string `validate:"omitempty,max=50"`
+}
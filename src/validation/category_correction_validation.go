@@ -0,0 +1,7 @@
+package validation
+
+type QueryCategoryCorrection struct {
+	Page   int    `validate:"omitempty,number,max=50"`
+	Limit  int    `validate:"omitempty,number,max=50"`
+	UserID string `validate:"required,uuid"`
+}
@@ -0,0 +1,28 @@
+package validation
+
+import "encoding/json"
+
+type CreateCategoryRule struct {
+	Category  string      `json:"category" validate:"required,max=50" example:"Groceries"`
+	Field     string      `json:"field" validate:"omitempty,oneof=name description merchant" example:"merchant"`
+	MatchType string      `json:"match_type" validate:"omitempty,oneof=contains regex" example:"contains"`
+	Pattern   string      `json:"pattern" validate:"omitempty,max=255" example:"indomaret"`
+	MinAmount json.Number `json:"min_amount" validate:"omitempty,money" swaggertype:"string" example:"10000"`
+	MaxAmount json.Number `json:"max_amount" validate:"omitempty,money" swaggertype:"string" example:"500000"`
+	Currency  string      `json:"currency" validate:"omitempty,iso4217" example:"IDR"`
+	Priority  int         `json:"priority" validate:"omitempty,min=0,max=1000" example:"10"`
+}
+
+// UpdateCategoryRule changes the fields that are set, an empty pattern or amount bound
+// removes that condition
+type UpdateCategoryRule struct {
+	Category  string       `json:"category,omitempty" validate:"omitempty,max=50" example:"Groceries"`
+	Field     *string      `json:"field,omitempty" validate:"omitempty,oneof=name description merchant" example:"merchant"`
+	MatchType *string      `json:"match_type,omitempty" validate:"omitempty,oneof=contains regex" example:"regex"`
+	Pattern   *string      `json:"pattern,omitempty" validate:"omitempty,max=255" example:"^indomaret"`
+	MinAmount *json.Number `json:"min_amount,omitempty" validate:"omitempty,len=0|money" swaggertype:"string" example:"10000"`
+	MaxAmount *json.Number `json:"max_amount,omitempty" validate:"omitempty,len=0|money" swaggertype:"string" example:"500000"`
+	Currency  *string      `json:"currency,omitempty" validate:"omitempty,iso4217" example:"IDR"`
+	Priority  *int         `json:"priority,omitempty" validate:"omitempty,min=0,max=1000" example:"10"`
+	IsActive  *bool        `json:"is_active,omitempty" example:"true"`
+}
@@ -0,0 +1,25 @@
+package validation
+
+type CreateCategory struct {
+	Name     string `json:"name" validate:"required,max=50" example:"Bensin"`
+	ParentID string `json:"parent_id,omitempty" validate:"omitempty,uuid" example:"95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5"`
+}
+
+type UpdateCategory struct {
+	Name     string `json:"name,omitempty" validate:"omitempty,max=50" example:"Food"`
+	IsActive *bool  `json:"is_active,omitempty" example:"true"`
+	// ParentID moves the category, an empty string moves it to the top level
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid|len=0" example:"95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5"`
+}
+
+type MergeCategory struct {
+	IntoCategoryID string `json:"into_category_id" validate:"required,uuid" example:"95eb84d2-0a32-4aef-b6c2-bfb5bbc686f5"`
+}
+
+type QueryCategory struct {
+	Page   int    `validate:"omitempty,number,max=50"`
+	Limit  int    `validate:"omitempty,number,max=50"`
+	Search string `validate:"omitempty,max=50"`
+	Status string `validate:"omitempty,oneof=active inactive all" example:"active"`
+	Owner  string `validate:"omitempty,uuid|eq=default" example:"default"`
+}
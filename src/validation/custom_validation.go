@@ -1,7 +1,10 @@
 package validation
 
 import (
+	"encoding/json"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -19,3 +22,48 @@ func Password(field validator.FieldLevel) bool {
 
 	return true
 }
+
+var decimalPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// Money accepts a non-negative decimal amount such as 12500.50. The number of
+// decimals the currency allows is checked when the amount is parsed.
+func Money(field validator.FieldLevel) bool {
+	value, ok := field.Field().Interface().(json.Number)
+	if !ok {
+		return false
+	}
+
+	return decimalPattern.MatchString(string(value))
+}
+
+// Rate accepts a positive decimal exchange rate such as 12150.25
+func Rate(field validator.FieldLevel) bool {
+	value, ok := field.Field().Interface().(string)
+	if !ok || !decimalPattern.MatchString(value) {
+		return false
+	}
+
+	return strings.Trim(value, "0.") != ""
+}
+
+// Period accepts a date (2006-01-02) or an RFC 3339 datetime
+func Period(field validator.FieldLevel) bool {
+	value, ok := field.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	_, _, err := ParsePeriod(value, time.UTC)
+	return err == nil
+}
+
+// ParsePeriod parses a period bound. dateOnly tells the value has no time of day,
+// dates start at midnight in loc.
+func ParsePeriod(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, true, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
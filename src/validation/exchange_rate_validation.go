@@ -0,0 +1,14 @@
+package validation
+
+type CreateExchangeRate struct {
+	FromCurrency  string `json:"from_currency" validate:"required,iso4217" example:"SGD"`
+	ToCurrency    string `json:"to_currency" validate:"required,iso4217,nefield=FromCurrency" example:"IDR"`
+	Rate          string `json:"rate" validate:"required,rate" example:"12150.25"`
+	EffectiveDate string `json:"effective_date" validate:"required,datetime=2006-01-02" example:"2025-07-01"`
+}
+
+type QueryExchangeRate struct {
+	Page     int    `validate:"omitempty,number,max=50"`
+	Limit    int    `validate:"omitempty,number,max=50"`
+	Currency string `validate:"omitempty,iso4217" example:"SGD"`
+}
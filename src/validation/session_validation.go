@@ -0,0 +1,12 @@
+package validation
+
+// ClaimSession proves the session is the caller's with the code the chat channel sent into it
+type ClaimSession struct {
+	UserSessionID string `json:"user_session_id" validate:"required,uuid" example:"5f0c4d3e-8f5c-4a43-9a4c-0a1f0f7f3b11"`
+	Code          string `json:"code" validate:"required,max=20" example:"K7QX3M9P"`
+}
+
+type CreateSessionClaimCode struct {
+	UserSessionID string `json:"user_session_id" validate:"required,uuid" example:"5f0c4d3e-8f5c-4a43-9a4c-0a1f0f7f3b11"`
+	Channel       string `json:"channel" validate:"required,max=50" example:"whatsapp"`
+}
@@ -1,14 +1,42 @@
 package validation
 
+import "encoding/json"
+
 type CreateSpending struct {
-	UserSessionID string  `json:"user_session_id" validate:"required,max=50" example:"user_session_id"`
-	Category      string  `json:"category" validate:"required,max=50" example:"food"`
-	CategoryID    string  `json:"category_id" validate:"required"`
-	Name          string  `json:"name" validate:"required,max=50" example:"fake name"`
-	Amount        float64 `json:"amount" validate:"required,number,min=0" example:"100.50"`
-	Description   string  `json:"description" validate:"omitempty,max=200" example:"fake description"`
-	Datetime      string  `json:"datetime" validate:"required" example:"2023-01-01T00:00:00Z"`
-	IsConfirm     bool    `json:"is_confirm" validate:"required" example:"true"`
+	UserSessionID string      `json:"user_session_id" validate:"required,max=50" example:"user_session_id"`
+	Category      string      `json:"category" validate:"required,max=50" example:"food"`
+	Name          string      `json:"name" validate:"required,max=50" example:"fake name"`
+	Amount        json.Number `json:"amount" validate:"required,money" swaggertype:"string" example:"100.50"`
+	Currency      string      `json:"currency" validate:"omitempty,iso4217" example:"IDR"`
+	Description   string      `json:"description" validate:"omitempty,max=200" example:"fake description"`
+	Merchant      string      `json:"merchant" validate:"omitempty,max=255" example:"Indomaret"`
+	Datetime      string      `json:"datetime" validate:"required,datetime=2006-01-02T15:04:05Z07:00" example:"2023-01-01T00:00:00Z"`
+	IsConfirm     bool        `json:"is_confirm" example:"true"`
+}
+
+type CreateReceipt struct {
+	UserSessionID string              `json:"user_session_id" validate:"required,max=50" example:"user_session_id"`
+	Merchant      string              `json:"merchant" validate:"omitempty,max=255" example:"Indomaret"`
+	Currency      string              `json:"currency" validate:"omitempty,iso4217" example:"IDR"`
+	Datetime      string              `json:"datetime" validate:"required,datetime=2006-01-02T15:04:05Z07:00" example:"2023-01-01T00:00:00Z"`
+	IsConfirm     bool                `json:"is_confirm" example:"true"`
+	Items         []CreateReceiptItem `json:"items" validate:"required,min=1,dive"`
+}
+
+type CreateReceiptItem struct {
+	Category    string      `json:"category" validate:"required,max=50" example:"food"`
+	Name        string      `json:"name" validate:"required,max=50" example:"fake name"`
+	Amount      json.Number `json:"amount" validate:"required,money" swaggertype:"string" example:"100.50"`
+	Description string      `json:"description" validate:"omitempty,max=200" example:"fake description"`
+}
+
+type UpdateSpending struct {
+	Category    string      `json:"category,omitempty" validate:"omitempty,max=50" example:"food"`
+	Name        string      `json:"name,omitempty" validate:"omitempty,max=50" example:"fake name"`
+	Amount      json.Number `json:"amount,omitempty" validate:"omitempty,money" swaggertype:"string" example:"100.50"`
+	Currency    string      `json:"currency,omitempty" validate:"omitempty,iso4217" example:"SGD"`
+	Description string      `json:"description,omitempty" validate:"omitempty,max=200" example:"fake description"`
+	Datetime    string      `json:"datetime,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" example:"2023-01-01T00:00:00Z"`
 }
 
 // type Spending struct {
@@ -29,14 +57,41 @@ type QuerySpending struct {
 	Page   int    `validate:"omitempty,number,max=50"`
 	Limit  int    `validate:"omitempty,number,max=50"`
 	Search string `validate:"omitempty,max=50"`
+	Status string `validate:"omitempty,oneof=pending confirmed" example:"pending"`
+	UserID string `validate:"required,uuid"`
+}
+
+type QuerySpendingCategory struct {
+	Search string `validate:"omitempty,max=50"`
+	UserID string `validate:"required,uuid"`
+}
+
+type QuerySpendingTimeseries struct {
+	UserID      string `validate:"required,uuid"`
+	Bucket      string `validate:"required,oneof=day week month" example:"day"`
+	PeriodStart string `validate:"required,period" example:"2025-07-01"`
+	PeriodEnd   string `validate:"required,period" example:"2025-07-31"`
+	CategoryID  string `validate:"omitempty,uuid"`
+	Rollup      bool
+	Timezone    string `validate:"omitempty,timezone" example:"Asia/Jakarta"`
+}
+
+type QuerySpendingComparison struct {
+	UserID     string `validate:"required,uuid"`
+	PeriodType string `validate:"required,oneof=weekly monthly yearly" example:"monthly"`
+	Date       string `validate:"omitempty,period" example:"2025-07-15"`
+	Rollup     bool
+	Timezone   string `validate:"omitempty,timezone" example:"Asia/Jakarta"`
 }
 
 type QuerySpendingSummary struct {
-	Page          int    `validate:"omitempty,number,max=50"`
-	Limit         int    `validate:"omitempty,number,max=50"`
-	Search        string `validate:"omitempty,max=50"`
-	UserSessionID string `validate:"required,max=50"`
-	PeriodStart   string `validate:"omitempty,datetime"`
-	PeriodEnd     string `validate:"omitempty,datetime"`
-	PeriodType    string `validate:"omitempty,oneof=daily weekly monthly custom yearly all" example:"daily"`
+	Page        int    `validate:"omitempty,number,max=50"`
+	Limit       int    `validate:"omitempty,number,max=50"`
+	Search      string `validate:"omitempty,max=50"`
+	UserID      string `validate:"required,uuid"`
+	PeriodStart string `validate:"required_if=PeriodType custom,required_with=PeriodEnd,omitempty,period" example:"2025-07-01"`
+	PeriodEnd   string `validate:"required_if=PeriodType custom,required_with=PeriodStart,omitempty,period" example:"2025-07-31"`
+	PeriodType  string `validate:"omitempty,oneof=daily weekly monthly custom yearly all" example:"daily"`
+	Rollup      bool
+	Timezone    string `validate:"omitempty,timezone" example:"Asia/Jakarta"`
 }
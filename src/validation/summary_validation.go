@@ -0,0 +1,6 @@
+package validation
+
+type RebuildSummaries struct {
+	UserID string `json:"user_id,omitempty" validate:"omitempty,uuid" example:"e088d183-9eea-4a11-8d5d-74d7ec91bdf5"`
+	DryRun bool   `json:"dry_run" example:"true"`
+}
@@ -4,13 +4,17 @@ type CreateUser struct {
 	Name     string `json:"name" validate:"required,max=50" example:"fake name"`
 	Email    string `json:"email" validate:"required,email,max=50" example:"fake@example.com"`
 	Password string `json:"password" validate:"required,min=8,max=20,password" example:"password1"`
-	Role     string `json:"role" validate:"required,oneof=user admin,max=50" example:"user"`
+	Role     string `json:"role" validate:"required,oneof=user admin channel,max=50" example:"user"`
 }
 
 type UpdateUser struct {
 	Name     string `json:"name,omitempty" validate:"omitempty,max=50" example:"fake name"`
 	Email    string `json:"email" validate:"omitempty,email,max=50" example:"fake@example.com"`
 	Password string `json:"password,omitempty" validate:"omitempty,min=8,max=20,password" example:"password1"`
+	// Timezone and BaseCurrency change the user's preference, an empty string resets it
+	// to the server default
+	Timezone     *string `json:"timezone,omitempty" validate:"omitempty,max=64,timezone|len=0" example:"Asia/Jakarta"`
+	BaseCurrency *string `json:"base_currency,omitempty" validate:"omitempty,iso4217|len=0" example:"IDR"`
 }
 
 type UpdatePassOrVerify struct {
@@ -3,21 +3,30 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var customMessages = map[string]string{
-	"required": "Field %s must be filled",
-	"email":    "Invalid email address for field %s",
-	"min":      "Field %s must have a minimum length of %s characters",
-	"max":      "Field %s must have a maximum length of %s characters",
-	"len":      "Field %s must be exactly %s characters long",
-	"number":   "Field %s must be a number",
-	"positive": "Field %s must be a positive number",
-	"alphanum": "Field %s must contain only alphanumeric characters",
-	"oneof":    "Invalid value for field %s",
-	"password": "Field %s must contain at least 1 letter and 1 number",
+	"required":      "Field %s must be filled",
+	"email":         "Invalid email address for field %s",
+	"min":           "Field %s must have a minimum length of %s characters",
+	"max":           "Field %s must have a maximum length of %s characters",
+	"len":           "Field %s must be exactly %s characters long",
+	"number":        "Field %s must be a number",
+	"positive":      "Field %s must be a positive number",
+	"alphanum":      "Field %s must contain only alphanumeric characters",
+	"oneof":         "Invalid value for field %s",
+	"password":      "Field %s must contain at least 1 letter and 1 number",
+	"period":        "Field %s must be a date (YYYY-MM-DD) or an RFC 3339 datetime",
+	"required_if":   "Field %s must be filled when %s",
+	"required_with": "Field %s must be filled together with %s",
+	"timezone":      "Field %s must be an IANA timezone such as Asia/Jakarta",
+	"money":         "Field %s must be a decimal amount such as 12500.50",
+	"iso4217":       "Field %s must be an ISO 4217 currency code such as IDR",
+	"rate":          "Field %s must be a positive decimal exchange rate such as 12150.25",
+	"nefield":       "Field %s must differ from %s",
 }
 
 func CustomErrorMessages(err error) map[string]string {
@@ -45,9 +54,13 @@ func generateErrorMessages(validationErrors validator.ValidationErrors) map[stri
 }
 
 func formatErrorMessage(customMessage string, err validator.FieldError, tag string) string {
-	if tag == "min" || tag == "max" || tag == "len" {
+	if tag == "min" || tag == "max" || tag == "len" || tag == "required_with" || tag == "nefield" {
 		return fmt.Sprintf(customMessage, err.Field(), err.Param())
 	}
+	if tag == "required_if" {
+		// The param is "Field value", e.g. "PeriodType custom"
+		return fmt.Sprintf(customMessage, err.Field(), strings.Replace(err.Param(), " ", " is ", 1))
+	}
 	return fmt.Sprintf(customMessage, err.Field())
 }
 
@@ -62,5 +75,17 @@ func Validator() *validator.Validate {
 		return nil
 	}
 
+	if err := validate.RegisterValidation("money", Money); err != nil {
+		return nil
+	}
+
+	if err := validate.RegisterValidation("period", Period); err != nil {
+		return nil
+	}
+
+	if err := validate.RegisterValidation("rate", Rate); err != nil {
+		return nil
+	}
+
 	return validate
 }
@@ -0,0 +1,31 @@
+package fixture
+
+import (
+	"app/src/model"
+	"app/test/helper"
+	"time"
+)
+
+var SpendingOne = &model.Spending{
+	Category:  "Food",
+	Name:      "Nasi goreng",
+	Amount:    helper.Money(25000),
+	Datetime:  time.Now(),
+	IsConfirm: true,
+}
+
+var SpendingTwo = &model.Spending{
+	Category:  "Transport",
+	Name:      "Bensin",
+	Amount:    helper.Money(100000),
+	Datetime:  time.Now(),
+	IsConfirm: true,
+}
+
+var SpendingOther = &model.Spending{
+	Category:  "Food",
+	Name:      "Bakso",
+	Amount:    helper.Money(15000),
+	Datetime:  time.Now(),
+	IsConfirm: true,
+}
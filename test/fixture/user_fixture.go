@@ -32,3 +32,12 @@ var Admin = &model.User{
 	Role:          "admin",
 	VerifiedEmail: false,
 }
+
+var Channel = &model.User{
+	ID:            uuid.New(),
+	Name:          "Channel",
+	Email:         "channel@gmail.com",
+	Password:      "password1",
+	Role:          "channel",
+	VerifiedEmail: false,
+}
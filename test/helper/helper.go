@@ -3,13 +3,22 @@ package helper
 import (
 	"app/src/config"
 	"app/src/model"
+	"app/src/service"
 	"app/src/utils"
+	"app/test"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +27,200 @@ func ClearAll(db *gorm.DB) {
 	ClearUsers(db)
 }
 
+func ClearSpendings(db *gorm.DB) {
+	err := db.Where("id is not null").Delete(&model.UserSession{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear user session data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.SessionClaimCode{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear session claim code data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.CategorySpendingSummary{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear spending summary data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.Spending{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear spending data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.Receipt{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear receipt data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.OutboxEvent{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear outbox event data : %+v", err)
+	}
+}
+
+func InsertSpending(db *gorm.DB, user *model.User, spendings ...*model.Spending) {
+	for _, spending := range spendings {
+		if user != nil {
+			spending.UserSessionID = user.ID
+		}
+
+		// Fixture categories are defaults
+		category := &model.Category{Name: spending.Category}
+		if err := db.Where("owner_id IS NULL").
+			FirstOrCreate(category, model.Category{Name: spending.Category}).Error; err != nil {
+			logrus.Errorf("Failed to create category: %+v", err)
+			continue
+		}
+		spending.CategoryID = &category.ID
+
+		// Fixtures are in the default currency unless they set a base amount
+		if spending.BaseAmount.Currency == "" {
+			spending.BaseAmount = spending.Amount
+			spending.ExchangeRate = "1"
+		}
+
+		if err := db.Create(spending).Error; err != nil {
+			logrus.Errorf("Failed to create spending: %+v", err)
+			continue
+		}
+
+		if !spending.IsConfirm {
+			continue
+		}
+
+		err := service.UpsertSummary(db, spending.UserSessionID, category.ID, category.Name,
+			spending.BaseAmount, spending.Datetime)
+		if err != nil {
+			logrus.Errorf("Failed to upsert spending summary: %+v", err)
+		}
+	}
+}
+
+// Money returns a whole amount in config.Currency, e.g. Money(25000) for Rp25.000
+func Money(amount int64) model.Money {
+	return model.MoneyFromFloat(float64(amount), config.Currency)
+}
+
+func InsertExchangeRate(db *gorm.DB, from, to, rate string, effectiveDate time.Time) *model.ExchangeRate {
+	exchangeRate := &model.ExchangeRate{
+		FromCurrency:  from,
+		ToCurrency:    to,
+		Rate:          rate,
+		EffectiveDate: effectiveDate,
+	}
+
+	if err := db.Create(exchangeRate).Error; err != nil {
+		logrus.Errorf("Failed to create exchange rate: %+v", err)
+	}
+
+	return exchangeRate
+}
+
+func ClearCategories(db *gorm.DB) {
+	err := db.Where("id is not null").Delete(&model.CategoryRule{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear category rule data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.CategoryAlias{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear category alias data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.CategoryCorrection{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear category correction data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.Budget{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear budget data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.Category{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear category data : %+v", err)
+	}
+
+	err = db.Where("id is not null").Delete(&model.CategoryLabelReview{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear category label review data : %+v", err)
+	}
+}
+
+// InsertCategory creates the default categories that are missing, like the migrations do
+func InsertCategory(db *gorm.DB, names ...string) {
+	for _, name := range names {
+		category := new(model.Category)
+		if err := db.Where("owner_id IS NULL").
+			FirstOrCreate(category, model.Category{Name: name}).Error; err != nil {
+			logrus.Errorf("Failed to create category: %+v", err)
+		}
+	}
+}
+
+// ResetCategoryData clears the users, spendings and categories of earlier tests and
+// inserts the given users
+func ResetCategoryData(db *gorm.DB, users ...*model.User) {
+	ClearAll(db)
+	ClearSpendings(db)
+	ClearCategories(db)
+	InsertUser(db, users...)
+}
+
+func ClearExchangeRates(db *gorm.DB) {
+	err := db.Where("id is not null").Delete(&model.ExchangeRate{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear exchange rate data : %+v", err)
+	}
+}
+
+func GetSpendingByID(db *gorm.DB, id string) (*model.Spending, error) {
+	spending := new(model.Spending)
+
+	result := db.First(spending, "id = ?", id)
+
+	return spending, result.Error
+}
+
+func InsertAttachment(db *gorm.DB, spending *model.Spending, filename string, content []byte) *model.Attachment {
+	attachment := &model.Attachment{
+		SpendingID:  &spending.ID,
+		Filename:    filename,
+		ContentType: http.DetectContentType(content),
+		Size:        int64(len(content)),
+		StorageKey:  spending.ID.String() + "/" + filename,
+	}
+
+	if err := service.NewLocalStorage(config.AttachmentDir).Save(attachment.StorageKey, content); err != nil {
+		logrus.Errorf("Failed to store attachment: %+v", err)
+	}
+
+	if err := db.Create(attachment).Error; err != nil {
+		logrus.Errorf("Failed to create attachment: %+v", err)
+	}
+
+	return attachment
+}
+
+func GetSpendingsBySession(db *gorm.DB, userSessionID uuid.UUID) ([]model.Spending, error) {
+	var spendings []model.Spending
+
+	result := db.Where("user_session_id = ?", userSessionID).Order("created_at asc").Find(&spendings)
+
+	return spendings, result.Error
+}
+
+func GetSpendingSummary(db *gorm.DB, userSessionID uuid.UUID, categoryName, periodType string) ([]model.CategorySpendingSummary, error) {
+	var summaries []model.CategorySpendingSummary
+
+	result := db.Where("user_session_id = ? AND category = ? AND period_type = ?",
+		userSessionID, categoryName, periodType).Find(&summaries)
+
+	return summaries, result.Error
+}
+
 func ClearUsers(db *gorm.DB) {
 	err := db.Where("id is not null").Delete(&model.User{}).Error
 	if err != nil {
@@ -165,3 +368,32 @@ func GetUserByID(db *gorm.DB, id string) (*model.User, error) {
 
 	return user, result.Error
 }
+
+// SendJSON sends body as JSON to the test app with an access token of user and returns
+// the response with its body
+func SendJSON(t *testing.T, user *model.User, method, url string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		bodyJSON, err := json.Marshal(body)
+		assert.Nil(t, err)
+		reader = bytes.NewReader(bodyJSON)
+	}
+
+	token, err := GenerateToken(user.ID.String(),
+		time.Now().Add(time.Minute*time.Duration(config.JWTAccessExp)), config.TokenTypeAccess)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(method, url, reader)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	apiResponse, err := test.App.Test(request)
+	assert.Nil(t, err)
+
+	responseBody, err := io.ReadAll(apiResponse.Body)
+	assert.Nil(t, err)
+
+	return apiResponse, responseBody
+}
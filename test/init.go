@@ -3,6 +3,7 @@ package test
 import (
 	"app/src/database"
 	"app/src/router"
+	"app/src/service"
 	"app/src/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,12 +14,22 @@ var App = fiber.New(fiber.Config{
 	CaseSensitive: true,
 	ErrorHandler:  utils.ErrorHandler,
 })
+
+// DB connects as the table owner and sees the rows of every session so tests can arrange
+// and inspect them. The app gets AppDB, which connects as the app role like the server,
+// so row-level security hides rows outside of an owner scope.
 var DB *gorm.DB
+var AppDB *gorm.DB
+
+// SummaryWorker is not started, tests call ProcessPending to apply queued summaries
+var SummaryWorker service.SummaryWorker
 var Log = utils.Log
 
 func init() {
 	// TODO: You can modify host and database configuration for tests
-	DB = database.Connect("localhost", "testdb")
-	router.Routes(App, DB)
+	DB = database.ConnectOwner("localhost", "testdb", "app.bypass_owner=on")
+	AppDB = database.Connect("localhost", "testdb")
+	SummaryWorker = service.NewSummaryWorker(AppDB)
+	router.Routes(App, AppDB, SummaryWorker)
 	App.Use(utils.NotFoundHandler)
 }
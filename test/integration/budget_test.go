@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetRoutes(t *testing.T) {
+	t.Cleanup(func() { helper.ClearCategories(test.DB) })
+
+	createBudget := func(t *testing.T, req validation.CreateBudget) (*http.Response, *model.Budget) {
+		apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/budgets", req)
+
+		responseBody := new(struct {
+			Data model.Budget `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return apiResponse, &responseBody.Data
+	}
+
+	getStatus := func(t *testing.T, user *model.User) []response.BudgetStatus {
+		apiResponse, bytes := helper.SendJSON(t, user, http.MethodGet, "/v1/budgets/status", nil)
+		assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+		responseBody := new(struct {
+			Data []response.BudgetStatus `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return responseBody.Data
+	}
+
+	t.Run("POST /v1/budgets", func(t *testing.T) {
+		t.Run("should return 201 and create the budget in the base currency", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertCategory(test.DB, "Food")
+
+			apiResponse, budget := createBudget(t, validation.CreateBudget{
+				Category: "food",
+				Period:   "monthly",
+				Amount:   "2000000",
+			})
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, fixture.UserOne.ID, budget.UserID)
+			assert.Equal(t, "Food", budget.Category.Name)
+			assert.Nil(t, budget.Category.OwnerID)
+			assert.Equal(t, helper.Money(2000000), budget.Amount)
+		})
+
+		t.Run("should return 409 for a second budget of the same category and period", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertCategory(test.DB, "Food")
+			createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "2000000"})
+
+			apiResponse, _ := createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "100000"})
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+
+			apiResponse, _ = createBudget(t, validation.CreateBudget{Category: "Food", Period: "weekly", Amount: "500000"})
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the budget is invalid", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertCategory(test.DB, "Food")
+
+			for _, req := range []validation.CreateBudget{
+				{Category: "Food", Period: "daily", Amount: "100000"},
+				{Category: "Food", Period: "monthly"},
+				{Category: "Food", Period: "monthly", Amount: "0"},
+				{Period: "monthly", Amount: "100000"},
+				{Category: "Fod", Period: "monthly", Amount: "100000"},
+			} {
+				apiResponse, _ := createBudget(t, req)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			}
+
+			var count int64
+			assert.Nil(t, test.DB.Model(&model.Category{}).Where("name = ?", "Fod").Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		})
+	})
+
+	t.Run("PATCH and DELETE /v1/budgets/:budgetId", func(t *testing.T) {
+		t.Run("should update the amount and period", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertCategory(test.DB, "Food")
+			_, budget := createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "2000000"})
+
+			amount := json.Number("500000")
+			period := "weekly"
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/budgets/"+budget.ID.String(),
+				validation.UpdateBudget{Amount: &amount, Period: &period})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			updated := new(struct {
+				Data model.Budget `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, updated))
+			assert.Equal(t, "weekly", updated.Data.Period)
+			assert.Equal(t, helper.Money(500000), updated.Data.Amount)
+		})
+
+		t.Run("should return 404 for the budget of another user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertCategory(test.DB, "Food")
+			_, budget := createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "2000000"})
+
+			amount := json.Number("1")
+			apiResponse, _ := helper.SendJSON(t, fixture.UserTwo, http.MethodPatch, "/v1/budgets/"+budget.ID.String(),
+				validation.UpdateBudget{Amount: &amount})
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserTwo, http.MethodDelete, "/v1/budgets/"+budget.ID.String(), nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserOne, http.MethodDelete, "/v1/budgets/"+budget.ID.String(), nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/budgets/status", func(t *testing.T) {
+		t.Run("should report the spending of the current period against each budget", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne, fixture.SpendingOther)
+			createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "100000"})
+			createBudget(t, validation.CreateBudget{Category: "Food", Period: "weekly", Amount: "30000"})
+
+			statuses := getStatus(t, fixture.UserOne)
+			assert.Len(t, statuses, 2)
+
+			monthly, weekly := statuses[0], statuses[1]
+			assert.Equal(t, "monthly", monthly.Period)
+			assert.Equal(t, helper.Money(100000), monthly.Limit)
+			assert.Equal(t, helper.Money(40000), monthly.Spent)
+			assert.Equal(t, helper.Money(60000), monthly.Remaining)
+			assert.Equal(t, 40.0, monthly.PercentUsed)
+			assert.True(t, monthly.Projected.Minor >= monthly.Spent.Minor)
+			assert.True(t, monthly.PeriodStart.Before(fixture.SpendingOne.Datetime))
+			assert.True(t, monthly.PeriodEnd.After(fixture.SpendingOne.Datetime))
+
+			assert.Equal(t, "weekly", weekly.Period)
+			assert.Equal(t, helper.Money(40000), weekly.Spent)
+			assert.Equal(t, helper.Money(-10000), weekly.Remaining)
+			assert.True(t, weekly.ProjectedOverspend.Minor >= helper.Money(10000).Minor)
+		})
+
+		t.Run("should not report the spending of another user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			createBudget(t, validation.CreateBudget{Category: "Food", Period: "monthly", Amount: "100000"})
+
+			assert.Len(t, getStatus(t, fixture.UserOne), 1)
+			assert.Empty(t, getStatus(t, fixture.UserTwo))
+		})
+	})
+}
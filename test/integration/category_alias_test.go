@@ -0,0 +1,221 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryAliasRoutes(t *testing.T) {
+	t.Cleanup(func() { helper.ClearCategories(test.DB) })
+
+	// Only Other exists, so the offline extractor's labels are unknown until aliased
+	createSpending := func(t *testing.T, text string) *response.CreateSpending {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		assert.Nil(t, writer.WriteField("text", text))
+		assert.Nil(t, writer.Close())
+
+		token, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("X-Expense-Extractor", "offline")
+
+		apiResponse, err := test.App.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		bytes, err := io.ReadAll(apiResponse.Body)
+		assert.Nil(t, err)
+
+		responseBody := new(struct {
+			Data response.CreateSpending `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return &responseBody.Data
+	}
+
+	createCategory := func(t *testing.T, name string) *model.Category {
+		apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/categories",
+			validation.CreateCategory{Name: name})
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		responseBody := new(struct {
+			Data model.Category `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return &responseBody.Data
+	}
+
+	getReviews := func(t *testing.T) *response.SuccessWithPaginate[model.CategoryLabelReview] {
+		apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodGet, "/v1/category-reviews", nil)
+		assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+		responseBody := new(response.SuccessWithPaginate[model.CategoryLabelReview])
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return responseBody
+	}
+
+	t.Run("Unknown extractor labels", func(t *testing.T) {
+		t.Run("should file the spending under Other and queue the label for review", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+
+			first := createSpending(t, "nasi goreng 25000")
+			second := createSpending(t, "bakso 15000")
+			assert.Equal(t, "Other", first.Category)
+			assert.Equal(t, "Other", second.Category)
+
+			stored, err := helper.GetSpendingByID(test.DB, first.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "Food", stored.CategoryLabel)
+
+			var created int64
+			assert.Nil(t, test.DB.Model(&model.Category{}).Where("LOWER(name) = 'food'").Count(&created).Error)
+			assert.Equal(t, int64(0), created)
+
+			reviews := getReviews(t)
+			assert.Len(t, reviews.Results, 1)
+			assert.Equal(t, "Food", reviews.Results[0].Label)
+			assert.Equal(t, "food", reviews.Results[0].NormalizedLabel)
+			assert.Equal(t, 2, reviews.Results[0].Occurrences)
+		})
+
+		t.Run("should match categories ignoring case and accents", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			createCategory(t, "FOÓD")
+
+			assert.Equal(t, "FOÓD", createSpending(t, "nasi goreng 25000").Category)
+			assert.Empty(t, getReviews(t).Results)
+		})
+	})
+
+	t.Run("POST /v1/category-aliases", func(t *testing.T) {
+		t.Run("should put spendings with the label into the alias' category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			minuman := createCategory(t, "Minuman")
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/category-aliases",
+				validation.CreateCategoryAlias{Label: "DRÍNK", CategoryID: minuman.ID.String()})
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			responseBody := new(struct {
+				Data response.CategoryAlias `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+			assert.Equal(t, "drink", responseBody.Data.NormalizedLabel)
+			assert.Equal(t, 0, responseBody.Data.Recategorized)
+
+			assert.Equal(t, "Minuman", createSpending(t, "kopi 10000").Category)
+			assert.Empty(t, getReviews(t).Results)
+		})
+
+		t.Run("should return 409 if the normalized label is already an alias", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			minuman := createCategory(t, "Minuman")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/category-aliases",
+				validation.CreateCategoryAlias{Label: "Drink", CategoryID: minuman.ID.String()})
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/category-aliases",
+				validation.CreateCategoryAlias{Label: " drínk! ", CategoryID: minuman.ID.String()})
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the category belongs to a user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			own := &model.Category{Name: "Jajan", OwnerID: &fixture.UserOne.ID, IsActive: true}
+			assert.Nil(t, test.DB.Create(own).Error)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/category-aliases",
+				validation.CreateCategoryAlias{Label: "Snack", CategoryID: own.ID.String()})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 if the user is not an admin", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			minuman := createCategory(t, "Minuman")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/category-aliases",
+				validation.CreateCategoryAlias{Label: "Drink", CategoryID: minuman.ID.String()})
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/category-reviews/:reviewId/resolve", func(t *testing.T) {
+		t.Run("should move the spendings waiting in Other and their summaries", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			spending := createSpending(t, "nasi goreng 25000")
+			apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/spending/"+spending.ID.String()+"/confirm", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			makanan := createCategory(t, "Makanan")
+			review := getReviews(t).Results[0]
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/category-reviews/"+review.ID.String()+"/resolve",
+				validation.ResolveCategoryLabelReview{CategoryID: makanan.ID.String()})
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			responseBody := new(struct {
+				Data response.CategoryAlias `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+			assert.Equal(t, "Food", responseBody.Data.Label)
+			assert.Equal(t, 1, responseBody.Data.Recategorized)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "Makanan", stored.Category)
+
+			other, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Other", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(0), other[0].TotalAmount)
+
+			food, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Makanan", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), food[0].TotalAmount)
+
+			assert.Empty(t, getReviews(t).Results)
+			assert.Equal(t, "Makanan", createSpending(t, "bakso 15000").Category)
+		})
+
+		t.Run("should return 404 for a dismissed review", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertCategory(test.DB, "Other")
+			createSpending(t, "nasi goreng 25000")
+			makanan := createCategory(t, "Makanan")
+			review := getReviews(t).Results[0]
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodDelete, "/v1/category-reviews/"+review.ID.String(), nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/category-reviews/"+review.ID.String()+"/resolve",
+				validation.ResolveCategoryLabelReview{CategoryID: makanan.ID.String()})
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryCorrectionRoutes(t *testing.T) {
+	t.Cleanup(func() { helper.ClearCategories(test.DB) })
+
+	createSpending := func(t *testing.T, text string) *response.CreateSpending {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		assert.Nil(t, writer.WriteField("text", text))
+		assert.Nil(t, writer.Close())
+
+		token, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("X-Expense-Extractor", "offline")
+
+		apiResponse, err := test.App.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		bytes, err := io.ReadAll(apiResponse.Body)
+		assert.Nil(t, err)
+
+		responseBody := new(struct {
+			Data response.CreateSpending `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return &responseBody.Data
+	}
+
+	recategorize := func(t *testing.T, spending *response.CreateSpending, category string) {
+		apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/spending/"+spending.ID.String(),
+			validation.UpdateSpending{Category: category})
+		assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+	}
+
+	getCorrections := func(t *testing.T) []model.CategoryCorrection {
+		apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet,
+			"/v1/users/"+fixture.UserOne.ID.String()+"/category-corrections", nil)
+		assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+		responseBody := new(response.SuccessWithPaginate[model.CategoryCorrection])
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return responseBody.Results
+	}
+
+	t.Run("Recording corrections", func(t *testing.T) {
+		t.Run("should record the name, extractor label and chosen category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo, fixture.Admin)
+			helper.InsertCategory(test.DB, "Food", "Other")
+			spending := createSpending(t, "martabak 30000")
+			assert.Equal(t, "Food", spending.Category)
+
+			recategorize(t, spending, "Jajan")
+
+			corrections := getCorrections(t)
+			assert.Len(t, corrections, 1)
+			assert.Equal(t, spending.ID, *corrections[0].SpendingID)
+			assert.Equal(t, "martabak", corrections[0].RawText)
+			assert.Equal(t, "Food", corrections[0].ExtractorLabel)
+			assert.Equal(t, "Jajan", corrections[0].Category.Name)
+		})
+
+		t.Run("should drop the correction when the spending is moved back", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo, fixture.Admin)
+			helper.InsertCategory(test.DB, "Food", "Other")
+			spending := createSpending(t, "martabak 30000")
+
+			recategorize(t, spending, "Jajan")
+			recategorize(t, spending, "Food")
+
+			assert.Empty(t, getCorrections(t))
+		})
+	})
+
+	t.Run("Suggesting categories", func(t *testing.T) {
+		t.Run("should override the extractor after consistent corrections of similar names", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo, fixture.Admin)
+			helper.InsertCategory(test.DB, "Food", "Other")
+			recategorize(t, createSpending(t, "martabak manis 30000"), "Jajan")
+			assert.Equal(t, "Food", createSpending(t, "martabak manis telur 32000").Category)
+
+			recategorize(t, createSpending(t, "martabak telur 35000"), "Jajan")
+			spending := createSpending(t, "martabak manis telur 40000")
+			assert.Equal(t, "Jajan", spending.Category)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "Food", stored.CategoryLabel)
+		})
+	})
+
+	t.Run("GET /v1/users/:userId/category-corrections/stats", func(t *testing.T) {
+		t.Run("should compare extracted and corrected spendings per label", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo, fixture.Admin)
+			helper.InsertCategory(test.DB, "Food", "Other")
+			recategorize(t, createSpending(t, "martabak 30000"), "Jajan")
+			createSpending(t, "nasi goreng 20000")
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodGet,
+				"/v1/users/"+fixture.UserOne.ID.String()+"/category-corrections/stats", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			responseBody := new(struct {
+				Data response.CategoryCorrectionStats `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			stats := responseBody.Data
+			assert.Equal(t, int64(2), stats.Extracted)
+			assert.Equal(t, int64(1), stats.Corrected)
+			assert.Equal(t, 0.5, stats.Accuracy)
+			assert.Len(t, stats.Labels, 1)
+			assert.Equal(t, "Food", stats.Labels[0].Label)
+			assert.Equal(t, int64(1), stats.Labels[0].Corrected)
+			assert.Equal(t, "Jajan", stats.Labels[0].CorrectedTo[0].Category)
+		})
+
+		t.Run("should return 403 for the stats of another user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo, fixture.Admin)
+			helper.InsertCategory(test.DB, "Food", "Other")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.UserTwo, http.MethodGet,
+				"/v1/users/"+fixture.UserOne.ID.String()+"/category-corrections/stats", nil)
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,216 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryRuleRoutes(t *testing.T) {
+	t.Cleanup(func() { helper.ClearCategories(test.DB) })
+
+	createRule := func(t *testing.T, req validation.CreateCategoryRule) (*http.Response, *response.CategoryRule) {
+		apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/category-rules", req)
+
+		responseBody := new(struct {
+			Data response.CategoryRule `json:"data"`
+		})
+		assert.Nil(t, json.Unmarshal(bytes, responseBody))
+		return apiResponse, &responseBody.Data
+	}
+
+	createSpending := func(t *testing.T, text, currency string) *model.Spending {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		assert.Nil(t, writer.WriteField("text", text))
+		if currency != "" {
+			assert.Nil(t, writer.WriteField("currency", currency))
+		}
+		assert.Nil(t, writer.Close())
+
+		token, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("X-Expense-Extractor", "offline")
+
+		apiResponse, err := test.App.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+		assert.Nil(t, err)
+		for i := range spendings {
+			if strings.HasPrefix(text, spendings[i].Name) {
+				return &spendings[i]
+			}
+		}
+		t.Fatalf("spending %q not created", text)
+		return nil
+	}
+
+	t.Run("POST /v1/category-rules", func(t *testing.T) {
+		t.Run("should return 201 and create the rule with an own category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			apiResponse, rule := createRule(t, validation.CreateCategoryRule{
+				Category:  "Groceries",
+				Pattern:   "indomaret",
+				MaxAmount: "500000",
+			})
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "name", rule.Field)
+			assert.Equal(t, "contains", rule.MatchType)
+			assert.Equal(t, "Groceries", rule.Category.Name)
+			assert.Equal(t, fixture.UserOne.ID, *rule.Category.OwnerID)
+			assert.Nil(t, rule.MinAmount)
+			assert.Equal(t, helper.Money(500000), *rule.MaxAmount)
+		})
+
+		t.Run("should return 400 if the rule is invalid", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			for _, req := range []validation.CreateCategoryRule{
+				{Category: "Groceries"},
+				{Category: "Groceries", Pattern: "(indomaret", MatchType: "regex"},
+				{Category: "Groceries", MinAmount: "50000", MaxAmount: "10000"},
+				{Category: "Groceries", Pattern: "indomaret", Field: "notes"},
+			} {
+				apiResponse, _ := createRule(t, req)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			}
+		})
+	})
+
+	t.Run("Categorizing new spendings", func(t *testing.T) {
+		t.Run("should use the category of the first matching rule by priority", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			createRule(t, validation.CreateCategoryRule{Category: "Snacks", Pattern: "indomaret", MaxAmount: "20000", Priority: 1})
+			createRule(t, validation.CreateCategoryRule{Category: "Groceries", Pattern: "^indo", MatchType: "regex", Priority: 2})
+
+			assert.Equal(t, "Snacks", createSpending(t, "Indomaret 15000", "").Category)
+			assert.Equal(t, "Groceries", createSpending(t, "indomaret 150000", "").Category)
+			assert.NotEqual(t, "Groceries", createSpending(t, "alfamart 150000", "").Category)
+		})
+
+		t.Run("should match the amount range of a foreign currency spending in the base currency", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.ClearExchangeRates(test.DB)
+			t.Cleanup(func() { helper.ClearExchangeRates(test.DB) })
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12000", time.Now().AddDate(0, 0, -7))
+			createRule(t, validation.CreateCategoryRule{Category: "Snacks", Pattern: "indomaret", MaxAmount: "200000"})
+
+			spending := createSpending(t, "indomaret kopi 10", "SGD")
+			assert.Equal(t, model.NewMoney(1000, "SGD"), spending.Amount)
+			assert.Equal(t, "Snacks", spending.Category)
+
+			assert.NotEqual(t, "Snacks", createSpending(t, "indomaret beras 20", "SGD").Category)
+		})
+
+		t.Run("should not list the rules of another user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			createRule(t, validation.CreateCategoryRule{Category: "Groceries", Pattern: "indomaret"})
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserTwo, http.MethodGet, "/v1/category-rules", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			responseBody := new(struct {
+				Data []response.CategoryRule `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+			assert.Empty(t, responseBody.Data)
+		})
+	})
+
+	t.Run("Previewing and applying a rule", func(t *testing.T) {
+		t.Run("should move the matching spendings and their summaries", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne, fixture.SpendingOther)
+			_, rule := createRule(t, validation.CreateCategoryRule{Category: "Bakso", Pattern: "bakso"})
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet, "/v1/category-rules/"+rule.ID.String()+"/preview", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			preview := new(response.SuccessWithPaginate[model.Spending])
+			assert.Nil(t, json.Unmarshal(bytes, preview))
+			assert.Len(t, preview.Results, 1)
+			assert.Equal(t, fixture.SpendingOther.ID, preview.Results[0].ID)
+
+			apiResponse, bytes = helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/category-rules/"+rule.ID.String()+"/apply", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			applied := new(struct {
+				Data response.ApplyCategoryRule `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, applied))
+			assert.Equal(t, 1, applied.Data.Recategorized)
+
+			spending, err := helper.GetSpendingByID(test.DB, fixture.SpendingOther.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "Bakso", spending.Category)
+
+			food, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), food[0].TotalAmount)
+
+			bakso, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Bakso", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(15000), bakso[0].TotalAmount)
+
+			apiResponse, bytes = helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/category-rules/"+rule.ID.String()+"/apply", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Nil(t, json.Unmarshal(bytes, applied))
+			assert.Equal(t, 0, applied.Data.Recategorized)
+		})
+
+		t.Run("should return 404 for the rule of another user", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			_, rule := createRule(t, validation.CreateCategoryRule{Category: "Bakso", Pattern: "bakso"})
+
+			apiResponse, _ := helper.SendJSON(t, fixture.UserTwo, http.MethodPost, "/v1/category-rules/"+rule.ID.String()+"/apply", nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserTwo, http.MethodDelete, "/v1/category-rules/"+rule.ID.String(), nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("PATCH /v1/category-rules/:ruleId", func(t *testing.T) {
+		t.Run("should replace the given conditions and remove emptied ones", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.UserTwo)
+			_, rule := createRule(t, validation.CreateCategoryRule{
+				Category: "Groceries", Pattern: "indomaret", MinAmount: "10000", MaxAmount: "500000",
+			})
+
+			field, empty := "merchant", json.Number("")
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/category-rules/"+rule.ID.String(),
+				validation.UpdateCategoryRule{Field: &field, MinAmount: &empty})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			updated := new(struct {
+				Data response.CategoryRule `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, updated))
+			assert.Equal(t, "merchant", updated.Data.Field)
+			assert.Equal(t, "indomaret", updated.Data.Pattern)
+			assert.Nil(t, updated.Data.MinAmount)
+			assert.Equal(t, helper.Money(500000), *updated.Data.MaxAmount)
+		})
+	})
+}
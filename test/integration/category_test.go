@@ -0,0 +1,443 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryRoutes(t *testing.T) {
+	// Deactivated categories would block the spendings of other tests
+	t.Cleanup(func() { helper.ClearCategories(test.DB) })
+
+	categoryNamed := func(t *testing.T, name string) *model.Category {
+		category := new(model.Category)
+		assert.Nil(t, test.DB.First(category, "name = ?", name).Error)
+		return category
+	}
+
+	t.Run("POST /v1/categories", func(t *testing.T) {
+		t.Run("should return 201 and create the category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/categories",
+				validation.CreateCategory{Name: "Food"})
+
+			responseBody := new(struct {
+				Data model.Category `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "Food", responseBody.Data.Name)
+			assert.True(t, responseBody.Data.IsActive)
+		})
+
+		t.Run("should return 409 if the name only differs in case", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/categories",
+				validation.CreateCategory{Name: "food"})
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 if the user is not an admin", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/categories",
+				validation.CreateCategory{Name: "Food"})
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("PATCH /v1/categories/:categoryId", func(t *testing.T) {
+		t.Run("should rename the category in its spendings and summaries", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			category := categoryNamed(t, "Food")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPatch, "/v1/categories/"+category.ID.String(),
+				validation.UpdateCategory{Name: "Makanan"})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			spending, err := helper.GetSpendingByID(test.DB, fixture.SpendingOne.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "Makanan", spending.Category)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Makanan", "daily")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+		})
+	})
+
+	t.Run("POST /v1/categories/:categoryId/deactivate", func(t *testing.T) {
+		t.Run("should hide the category and reject new spendings in it", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			category := categoryNamed(t, "Food")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/categories/"+category.ID.String()+"/deactivate", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodGet, "/v1/categories", nil)
+			responseBody := new(response.SuccessWithPaginate[model.Category])
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(0), responseBody.TotalResults)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				validation.UpdateSpending{Category: "Transport"})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				validation.UpdateSpending{Category: "Food"})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("Per-user categories", func(t *testing.T) {
+		ownCategory := func(t *testing.T, user *model.User, name string) *model.Category {
+			category := &model.Category{Name: name, OwnerID: &user.ID, IsActive: true}
+			assert.Nil(t, test.DB.Create(category).Error)
+			return category
+		}
+
+		listCategories := func(t *testing.T, user *model.User) []string {
+			apiResponse, bytes := helper.SendJSON(t, user, http.MethodGet, "/v1/spending/categories", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			responseBody := new(response.SuccessWithPaginate[model.Category])
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			names := make([]string, len(responseBody.Results))
+			for i, category := range responseBody.Results {
+				names[i] = category.Name
+			}
+			return names
+		}
+
+		t.Run("should list the defaults merged with the user's own categories", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertUser(test.DB, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserTwo, fixture.SpendingOne)
+			ownCategory(t, fixture.UserOne, "Kopi")
+			ownCategory(t, fixture.UserOne, "food")
+
+			assert.ElementsMatch(t, []string{"Kopi", "food"}, listCategories(t, fixture.UserOne))
+			assert.ElementsMatch(t, []string{"Food"}, listCategories(t, fixture.UserTwo))
+		})
+
+		t.Run("should resolve a spending's category within its owner's scope", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertUser(test.DB, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			helper.InsertSpending(test.DB, fixture.UserTwo, fixture.SpendingTwo)
+			kopi := ownCategory(t, fixture.UserOne, "Kopi")
+			food := categoryNamed(t, "Food")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				validation.UpdateSpending{Category: "kopi"})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			spending, err := helper.GetSpendingByID(test.DB, fixture.SpendingOne.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, kopi.ID, *spending.CategoryID)
+			assert.Equal(t, "Kopi", spending.Category)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserTwo, http.MethodPatch, "/v1/spending/"+fixture.SpendingTwo.ID.String(),
+				validation.UpdateSpending{Category: "kopi"})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			spending, err = helper.GetSpendingByID(test.DB, fixture.SpendingTwo.ID.String())
+			assert.Nil(t, err)
+			assert.NotEqual(t, kopi.ID, *spending.CategoryID)
+			assert.Equal(t, "kopi", spending.Category)
+
+			created := new(model.Category)
+			assert.Nil(t, test.DB.First(created, "id = ?", spending.CategoryID).Error)
+			assert.Equal(t, fixture.UserTwo.ID, *created.OwnerID)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserTwo, http.MethodPatch, "/v1/spending/"+fixture.SpendingTwo.ID.String(),
+				validation.UpdateSpending{Category: "FOOD"})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			spending, err = helper.GetSpendingByID(test.DB, fixture.SpendingTwo.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, food.ID, *spending.CategoryID)
+		})
+
+		t.Run("should not merge a category into another user's category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertUser(test.DB, fixture.UserTwo)
+			source := ownCategory(t, fixture.UserOne, "Kopi")
+			target := ownCategory(t, fixture.UserTwo, "Coffee")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/categories/"+source.ID.String()+"/merge",
+				validation.MergeCategory{IntoCategoryID: target.ID.String()})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 409 if a session of the same owner already has the name", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			chatSession := uuid.New()
+			assert.Nil(t, test.DB.Create(&model.UserSession{
+				UserID: fixture.UserOne.ID, UserSessionID: chatSession, Channel: "whatsapp",
+			}).Error)
+			ownCategory(t, fixture.UserOne, "Kopi")
+			teh := &model.Category{Name: "Teh", OwnerID: &chatSession, IsActive: true}
+			assert.Nil(t, test.DB.Create(teh).Error)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPatch, "/v1/categories/"+teh.ID.String(),
+				validation.UpdateCategory{Name: "kopi"})
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("Category tree", func(t *testing.T) {
+		create := func(t *testing.T, name string, parent *model.Category) (*http.Response, *model.Category) {
+			req := validation.CreateCategory{Name: name}
+			if parent != nil {
+				req.ParentID = parent.ID.String()
+			}
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost, "/v1/categories", req)
+
+			responseBody := new(struct {
+				Data model.Category `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+			return apiResponse, &responseBody.Data
+		}
+
+		transportTree := func(t *testing.T) (transport, bensin, parkir *model.Category) {
+			_, transport = create(t, "Transport", nil)
+			_, bensin = create(t, "Bensin", transport)
+			_, parkir = create(t, "Parkir", transport)
+			return transport, bensin, parkir
+		}
+
+		t.Run("should return subcategories nested under their parent", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			transport, _, _ := transportTree(t)
+			create(t, "Food", nil)
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet, "/v1/spending/categories/tree", nil)
+
+			responseBody := new(struct {
+				Data []response.CategoryNode `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, responseBody.Data, 2)
+			assert.Equal(t, "Food", responseBody.Data[0].Name)
+			assert.Equal(t, transport.ID, responseBody.Data[1].ID)
+			assert.Len(t, responseBody.Data[1].Children, 2)
+			assert.Equal(t, "Bensin", responseBody.Data[1].Children[0].Name)
+			assert.Equal(t, transport.ID, *responseBody.Data[1].Children[0].ParentID)
+		})
+
+		t.Run("should return 400 if a move would create a cycle", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			transport, bensin, _ := transportTree(t)
+
+			parentID := bensin.ID.String()
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPatch, "/v1/categories/"+transport.ID.String(),
+				validation.UpdateCategory{ParentID: &parentID})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			parentID = transport.ID.String()
+			apiResponse, _ = helper.SendJSON(t, fixture.Admin, http.MethodPatch, "/v1/categories/"+transport.ID.String(),
+				validation.UpdateCategory{ParentID: &parentID})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if categories would nest too deep", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			_, bensin, parkir := transportTree(t)
+
+			apiResponse, pertalite := create(t, "Pertalite", bensin)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse, _ = create(t, "Subsidi", pertalite)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			parentID := parkir.ID.String()
+			apiResponse, _ = helper.SendJSON(t, fixture.Admin, http.MethodPatch, "/v1/categories/"+bensin.ID.String(),
+				validation.UpdateCategory{ParentID: &parentID})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 409 when deleting a category with subcategories", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			transport, _, _ := transportTree(t)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodDelete, "/v1/categories/"+transport.ID.String(), nil)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should roll subcategory totals up into every ancestor", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			transport, bensin, _ := transportTree(t)
+			create(t, "Pertalite", bensin)
+			now := time.Now()
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Pertalite", Name: "Isi bensin", Amount: helper.Money(60000), Datetime: now, IsConfirm: true},
+				&model.Spending{Category: "Bensin", Name: "Pertamax", Amount: helper.Money(40000), Datetime: now, IsConfirm: true},
+				&model.Spending{Category: "Parkir", Name: "Parkir mall", Amount: helper.Money(5000), Datetime: now, IsConfirm: true},
+				&model.Spending{Category: "Transport", Name: "Tol", Amount: helper.Money(20000), Datetime: now, IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Bakso", Amount: helper.Money(15000), Datetime: now, IsConfirm: true},
+			)
+
+			summary := func(t *testing.T, query string) map[string]model.Money {
+				apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet, "/v1/spending/summary?"+query, nil)
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+				responseBody := new(response.SuccessWithPaginate[response.SummarySpending])
+				assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+				totals := make(map[string]model.Money)
+				for _, row := range responseBody.Results {
+					totals[row.Category] = row.TotalAmount
+				}
+				return totals
+			}
+
+			assert.Equal(t, map[string]model.Money{
+				"Pertalite": helper.Money(60000),
+				"Bensin":    helper.Money(40000),
+				"Parkir":    helper.Money(5000),
+				"Transport": helper.Money(20000),
+				"Food":      helper.Money(15000),
+			}, summary(t, ""))
+
+			assert.Equal(t, map[string]model.Money{
+				"Pertalite": helper.Money(60000),
+				"Bensin":    helper.Money(100000),
+				"Parkir":    helper.Money(5000),
+				"Transport": helper.Money(125000),
+				"Food":      helper.Money(15000),
+			}, summary(t, "rollup=true"))
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet,
+				"/v1/spending/comparison?period_type=monthly&rollup=true", nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			comparison := new(struct {
+				Data response.SpendingComparison `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, comparison))
+			assert.Equal(t, helper.Money(140000), comparison.Data.CurrentTotal)
+
+			today := now.Format(time.DateOnly)
+			timeseries := func(t *testing.T, query string) model.Money {
+				apiResponse, bytes := helper.SendJSON(t, fixture.UserOne, http.MethodGet,
+					"/v1/spending/timeseries?period_start="+today+"&period_end="+today+"&category_id="+transport.ID.String()+query, nil)
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+				responseBody := new(struct {
+					Data response.SpendingTimeseries `json:"data"`
+				})
+				assert.Nil(t, json.Unmarshal(bytes, responseBody))
+				return responseBody.Data.Total
+			}
+
+			assert.Equal(t, helper.Money(20000), timeseries(t, ""))
+			assert.Equal(t, helper.Money(125000), timeseries(t, "&rollup=true"))
+		})
+	})
+
+	t.Run("DELETE /v1/categories/:categoryId", func(t *testing.T) {
+		t.Run("should return 409 if spendings use the category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			category := categoryNamed(t, "Food")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodDelete, "/v1/categories/"+category.ID.String(), nil)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 200 and delete an unused category", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			category := categoryNamed(t, "Food")
+			assert.Nil(t, test.DB.Delete(&model.Spending{}, "id = ?", fixture.SpendingOne.ID).Error)
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodDelete, "/v1/categories/"+category.ID.String(), nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 0)
+		})
+	})
+
+	t.Run("POST /v1/categories/:categoryId/merge", func(t *testing.T) {
+		t.Run("should move spendings and add up the summaries of the same period", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			now := time.Now()
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Food", Name: "Nasi", Amount: helper.Money(25000), Datetime: now, IsConfirm: true},
+				&model.Spending{Category: "makanan", Name: "Soto", Amount: helper.Money(15000), Datetime: now, IsConfirm: true},
+			)
+			food, makanan := categoryNamed(t, "Food"), categoryNamed(t, "makanan")
+
+			apiResponse, bytes := helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/categories/"+makanan.ID.String()+"/merge",
+				validation.MergeCategory{IntoCategoryID: food.ID.String()})
+
+			responseBody := new(struct {
+				Data response.MergeCategory `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, food.ID, responseBody.Data.Category.ID)
+			assert.Equal(t, int64(1), responseBody.Data.Spendings)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			for _, spending := range spendings {
+				assert.Equal(t, food.ID, *spending.CategoryID)
+				assert.Equal(t, "Food", spending.Category)
+			}
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", periodType)
+				assert.Nil(t, err)
+				assert.Len(t, summaries, 1)
+				assert.Equal(t, helper.Money(40000), summaries[0].TotalAmount)
+			}
+
+			var count int64
+			assert.Nil(t, test.DB.Model(&model.Category{}).Where("id = ?", makanan.ID).Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+			assert.Nil(t, test.DB.Model(&model.CategorySpendingSummary{}).Where("category_id = ?", makanan.ID).Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		})
+
+		t.Run("should return 400 if a category is merged into itself", func(t *testing.T) {
+			helper.ResetCategoryData(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			category := categoryNamed(t, "Food")
+
+			apiResponse, _ := helper.SendJSON(t, fixture.Admin, http.MethodPost,
+				"/v1/categories/"+category.ID.String()+"/merge",
+				validation.MergeCategory{IntoCategoryID: category.ID.String()})
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+}
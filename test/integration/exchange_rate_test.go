@@ -0,0 +1,250 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRateRoutes(t *testing.T) {
+	accessToken := func(t *testing.T, user *model.User) string {
+		token, err := fixture.AccessToken(user)
+		assert.Nil(t, err)
+		return token
+	}
+
+	t.Run("POST /v1/exchange-rates", func(t *testing.T) {
+		create := func(t *testing.T, user *model.User, requestBody validation.CreateExchangeRate) (*http.Response, *model.ExchangeRate) {
+			bodyJSON, err := json.Marshal(requestBody)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/exchange-rates", strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data model.ExchangeRate `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, &responseBody.Data
+		}
+
+		sgdToIDR := validation.CreateExchangeRate{
+			FromCurrency: "SGD", ToCurrency: "IDR", Rate: "12150.25", EffectiveDate: "2025-07-01",
+		}
+
+		t.Run("should return 201 and create the rate", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse, rate := create(t, fixture.Admin, sgdToIDR)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "SGD", rate.FromCurrency)
+			assert.Equal(t, "IDR", rate.ToCurrency)
+			assert.Equal(t, "12150.25", rate.Rate)
+		})
+
+		t.Run("should replace the rate the pair already had that day", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			_, first := create(t, fixture.Admin, sgdToIDR)
+
+			corrected := sgdToIDR
+			corrected.Rate = "12200"
+			apiResponse, rate := create(t, fixture.Admin, corrected)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, first.ID, rate.ID)
+			assert.Equal(t, "12200", rate.Rate)
+		})
+
+		t.Run("should return 400 if the rate is not positive or the pair is the same currency", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			invalid := sgdToIDR
+			invalid.Rate = "0"
+			apiResponse, _ := create(t, fixture.Admin, invalid)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			invalid = sgdToIDR
+			invalid.ToCurrency = "SGD"
+			apiResponse, _ = create(t, fixture.Admin, invalid)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 if the user is not an admin", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			apiResponse, _ := create(t, fixture.UserOne, sgdToIDR)
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/exchange-rates/import", func(t *testing.T) {
+		upload := func(t *testing.T, user *model.User, csv string) *http.Response {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("file", "rates.csv")
+			assert.Nil(t, err)
+			_, err = part.Write([]byte(csv))
+			assert.Nil(t, err)
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/exchange-rates/import", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			return apiResponse
+		}
+
+		t.Run("should return 200 and store every row", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse := upload(t, fixture.Admin, "from_currency,to_currency,rate,effective_date\n"+
+				"SGD,IDR,12150.25,2025-07-01\n"+
+				"jpy,idr,112.4,2025-07-01\n")
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.ImportExchangeRates `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, 2, responseBody.Data.Imported)
+
+			var count int64
+			assert.Nil(t, test.DB.Model(&model.ExchangeRate{}).Where("from_currency = ?", "JPY").Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+		})
+
+		t.Run("should return 400 and store nothing if a row is invalid", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse := upload(t, fixture.Admin, "from_currency,to_currency,rate,effective_date\n"+
+				"SGD,IDR,12150.25,2025-07-01\n"+
+				"JPY,IDR,-1,2025-07-01\n")
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			var count int64
+			assert.Nil(t, test.DB.Model(&model.ExchangeRate{}).Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		})
+
+		t.Run("should return 400 naming the line if a pair's rate of a day is repeated", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse := upload(t, fixture.Admin, "from_currency,to_currency,rate,effective_date\n"+
+				"SGD,IDR,12150.25,2025-07-01\n"+
+				"JPY,IDR,105.5,2025-07-01\n"+
+				"sgd,idr,12200,2025-07-01\n")
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			body, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+			assert.Contains(t, string(body), "Line 4")
+
+			var count int64
+			assert.Nil(t, test.DB.Model(&model.ExchangeRate{}).Count(&count).Error)
+			assert.Equal(t, int64(0), count)
+		})
+
+		t.Run("should return 400 if the header is missing", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse := upload(t, fixture.Admin, "SGD,IDR,12150.25,2025-07-01\n")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/exchange-rates", func(t *testing.T) {
+		t.Run("should return 200 and the rates of a currency", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			july := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12150.25", july)
+			helper.InsertExchangeRate(test.DB, "JPY", "IDR", "112.4", july)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/exchange-rates?currency=SGD", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[model.ExchangeRate])
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+			assert.Equal(t, "SGD", responseBody.Results[0].FromCurrency)
+		})
+	})
+
+	t.Run("DELETE /v1/exchange-rates/:rateId", func(t *testing.T) {
+		t.Run("should return 200 and delete the rate", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+			rate := helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12150.25", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/exchange-rates/"+rate.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.Admin))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			request = httptest.NewRequest(http.MethodDelete, "/v1/exchange-rates/"+rate.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.Admin))
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
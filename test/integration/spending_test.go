@@ -0,0 +1,1563 @@
+package integration
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestSpendingRoutes(t *testing.T) {
+	accessToken := func(t *testing.T, user *model.User) string {
+		token, err := fixture.AccessToken(user)
+		assert.Nil(t, err)
+		return token
+	}
+
+	claimCode := func(t *testing.T, userSessionID uuid.UUID) string {
+		bodyJSON, err := json.Marshal(validation.CreateSessionClaimCode{UserSessionID: userSessionID.String(), Channel: "whatsapp"})
+		assert.Nil(t, err)
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/channel/sessions/claim-codes", strings.NewReader(string(bodyJSON)))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.Channel))
+
+		apiResponse, err := test.App.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		responseBody := new(struct {
+			Data response.SessionClaimCode `json:"data"`
+		})
+		assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		return responseBody.Data.Code
+	}
+
+	t.Run("Spending authentication", func(t *testing.T) {
+		t.Run("should return 401 if access token is missing", func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/list", nil)
+			request.Header.Set("session_user_id", uuid.NewString())
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusUnauthorized, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("Row-level security", func(t *testing.T) {
+		t.Run("should hide every spending and summary outside of an owner scope", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			var spendings, summaries int64
+			assert.Nil(t, test.AppDB.Model(&model.Spending{}).Count(&spendings).Error)
+			assert.Nil(t, test.AppDB.Model(&model.CategorySpendingSummary{}).Count(&summaries).Error)
+			assert.Zero(t, spendings)
+			assert.Zero(t, summaries)
+
+			err := test.AppDB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec("SELECT set_config('app.user_session_ids', ?, true)", fixture.UserOne.ID.String()).Error; err != nil {
+					return err
+				}
+				return tx.Model(&model.Spending{}).Count(&spendings).Error
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), spendings)
+		})
+	})
+
+	t.Run("POST /v1/spending", func(t *testing.T) {
+		t.Run("should return 201 and create a spending with the offline extractor", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "kopi susu 25000"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 1)
+			assert.Equal(t, "kopi susu", spendings[0].Name)
+			assert.Equal(t, helper.Money(25000), spendings[0].Amount)
+		})
+
+		t.Run("should return 201 and create one spending per receipt line item", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "beras 65rb\nbensin 100k\nkopi susu 25rb"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.CreateReceipt `json:"data"`
+			})
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(190000), responseBody.Data.Total)
+			assert.Len(t, responseBody.Data.Spendings, 3)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 3)
+			for _, spending := range spendings {
+				assert.Equal(t, responseBody.Data.ID, *spending.ReceiptID)
+				assert.False(t, spending.IsConfirm)
+			}
+		})
+
+		t.Run("should store the transaction datetime and bucket summaries by it", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertCategory(test.DB, "Transport")
+
+			lastMonth := time.Now().UTC().AddDate(0, -1, 0).Truncate(time.Second)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "bensin 100k"))
+			assert.Nil(t, writer.WriteField("datetime", lastMonth.Format(time.RFC3339)))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 1)
+			assert.True(t, lastMonth.Equal(spendings[0].Datetime))
+
+			request = httptest.NewRequest(http.MethodPost, "/v1/spending/"+spendings[0].ID.String()+"/confirm", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Transport", "monthly")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, lastMonth.Month(), summaries[0].PeriodStart.In(lastMonth.Location()).Month())
+		})
+
+		t.Run("should return 400 if the datetime is not RFC 3339", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "bensin 100k"))
+			assert.Nil(t, writer.WriteField("datetime", "kemarin"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the extractor is unknown", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "kopi susu 25000"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "unknown")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/spending/sessions", func(t *testing.T) {
+		t.Run("should return 201 and expose the claimed session's spendings", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Channel)
+
+			chatSession := uuid.New()
+			fixture.SpendingOther.UserSessionID = chatSession
+			helper.InsertSpending(test.DB, nil, fixture.SpendingOther)
+
+			code := claimCode(t, chatSession)
+
+			bodyJSON, err := json.Marshal(validation.ClaimSession{UserSessionID: chatSession.String(), Code: code})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending/sessions", strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			session := new(model.UserSession)
+			assert.Nil(t, test.DB.First(session, "user_session_id = ?", chatSession).Error)
+			assert.Equal(t, "whatsapp", session.Channel)
+
+			var codes int64
+			test.DB.Model(&model.SessionClaimCode{}).Where("user_session_id = ?", chatSession).Count(&codes)
+			assert.Equal(t, int64(0), codes)
+
+			request = httptest.NewRequest(http.MethodGet, "/v1/spending/"+fixture.SpendingOther.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 without a valid claim code", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Channel)
+
+			chatSession := uuid.New()
+			fixture.SpendingOther.UserSessionID = chatSession
+			helper.InsertSpending(test.DB, nil, fixture.SpendingOther)
+
+			claim := func(code string) int {
+				bodyJSON, err := json.Marshal(validation.ClaimSession{UserSessionID: chatSession.String(), Code: code})
+				assert.Nil(t, err)
+
+				request := httptest.NewRequest(http.MethodPost, "/v1/spending/sessions", strings.NewReader(string(bodyJSON)))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+				apiResponse, err := test.App.Test(request)
+				assert.Nil(t, err)
+				return apiResponse.StatusCode
+			}
+
+			// No code was issued for the session
+			assert.Equal(t, http.StatusForbidden, claim("ABCD2345"))
+
+			code := claimCode(t, chatSession)
+			wrong := "ABCD2345"
+			if code == wrong {
+				wrong = "WXYZ6789"
+			}
+			assert.Equal(t, http.StatusForbidden, claim(wrong))
+
+			// A code stops working once it expires
+			err := test.DB.Model(&model.SessionClaimCode{}).
+				Where("user_session_id = ?", chatSession).
+				Update("expires_at", time.Now().Add(-time.Minute)).Error
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusForbidden, claim(code))
+
+			var sessions int64
+			test.DB.Model(&model.UserSession{}).Where("user_session_id = ?", chatSession).Count(&sessions)
+			assert.Equal(t, int64(0), sessions)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/"+fixture.SpendingOther.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 409 if session is already claimed by another user", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			bodyJSON, err := json.Marshal(validation.ClaimSession{UserSessionID: fixture.UserTwo.ID.String(), Code: "ABCD2345"})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending/sessions", strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 when a user without manageChannelSessions issues a claim code", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Admin)
+
+			bodyJSON, err := json.Marshal(validation.CreateSessionClaimCode{UserSessionID: uuid.NewString(), Channel: "whatsapp"})
+			assert.Nil(t, err)
+
+			for _, user := range []*model.User{fixture.UserOne, fixture.Admin} {
+				request := httptest.NewRequest(http.MethodPost, "/v1/channel/sessions/claim-codes", strings.NewReader(string(bodyJSON)))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+
+				apiResponse, err := test.App.Test(request)
+				assert.Nil(t, err)
+
+				assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+			}
+		})
+	})
+
+	t.Run("POST /v1/channel/spendings", func(t *testing.T) {
+		channelSpending := func(t *testing.T, userSessionID string, user *model.User) *http.Response {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("user_session_id", userSessionID))
+			assert.Nil(t, writer.WriteField("text", "kopi susu 25000"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/channel/spendings", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			return apiResponse
+		}
+
+		t.Run("should return 201 and move the spending to the user claiming the session", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Channel)
+
+			chatSession := uuid.New()
+			apiResponse := channelSpending(t, chatSession.String(), fixture.Channel)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, chatSession)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 1)
+			assert.Equal(t, "kopi susu", spendings[0].Name)
+
+			claim := validation.ClaimSession{UserSessionID: chatSession.String(), Code: claimCode(t, chatSession)}
+			apiResponse, _ = helper.SendJSON(t, fixture.UserOne, http.MethodPost, "/v1/spending/sessions", claim)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse, _ = helper.SendJSON(t, fixture.UserOne, http.MethodGet, "/v1/spending/"+spendings[0].ID.String(), nil)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 for a user's own session", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Channel)
+
+			apiResponse := channelSpending(t, fixture.UserOne.ID.String(), fixture.Channel)
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 0)
+		})
+
+		t.Run("should return 403 without the channel role", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			apiResponse := channelSpending(t, uuid.NewString(), fixture.UserOne)
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/spending/:spendingId", func(t *testing.T) {
+		t.Run("should return 200 and the spending if it exists", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/"+fixture.SpendingOne.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Contains(t, string(bytes), fixture.SpendingOne.ID.String())
+		})
+
+		t.Run("should return 404 if spending is not found", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/"+uuid.NewString(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if spending id is not a valid uuid", func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/invalidId", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/spending/summary with a custom range", func(t *testing.T) {
+		yesterday := time.Now().AddDate(0, 0, -1)
+		at := func(hour int) time.Time {
+			return time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), hour, 0, 0, 0, time.Local)
+		}
+
+		insertSpendings := func() {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Food", Name: "Sarapan", Amount: helper.Money(20000), Datetime: at(8), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Makan siang", Amount: helper.Money(30000), Datetime: at(13), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Bulan lalu", Amount: helper.Money(90000), Datetime: at(13).AddDate(0, -1, 0), IsConfirm: true},
+			)
+		}
+
+		getSummary := func(t *testing.T, query string) (*http.Response, *response.SuccessWithPaginate[response.SummarySpending]) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/summary?"+query, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[response.SummarySpending])
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, responseBody
+		}
+
+		t.Run("should total whole days from the daily summaries", func(t *testing.T) {
+			insertSpendings()
+			day := yesterday.Format(time.DateOnly)
+
+			apiResponse, responseBody := getSummary(t, "period_start="+day+"&period_end="+day)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, helper.Money(50000), responseBody.Results[0].TotalAmount)
+			assert.Equal(t, "custom", responseBody.Results[0].PeriodType)
+		})
+
+		t.Run("should total a range within a day from the spendings", func(t *testing.T) {
+			insertSpendings()
+			start := url.QueryEscape(at(7).Format(time.RFC3339))
+			end := url.QueryEscape(at(12).Format(time.RFC3339))
+
+			apiResponse, responseBody := getSummary(t, "period_type=custom&period_start="+start+"&period_end="+end)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, helper.Money(20000), responseBody.Results[0].TotalAmount)
+		})
+
+		t.Run("should return the range total", func(t *testing.T) {
+			insertSpendings()
+			start := at(13).AddDate(0, -1, 0).Format(time.DateOnly)
+			end := yesterday.Format(time.DateOnly)
+
+			request := httptest.NewRequest(http.MethodGet,
+				"/v1/spending/summary/total?period_start="+start+"&period_end="+end, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.TotalSummarySpending `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(140000), responseBody.Data.Total)
+		})
+
+		t.Run("should total the current period of a period type without a range", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Food", Name: "Hari ini", Amount: helper.Money(20000), Datetime: time.Now(), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Tahun lalu", Amount: helper.Money(90000), Datetime: time.Now().AddDate(0, 0, -400), IsConfirm: true},
+			)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				apiResponse, responseBody := getSummary(t, "period_type="+periodType)
+
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode, periodType)
+				assert.Len(t, responseBody.Results, 1, periodType)
+				assert.Equal(t, helper.Money(20000), responseBody.Results[0].TotalAmount, periodType)
+				assert.Equal(t, periodType, responseBody.Results[0].PeriodType, periodType)
+			}
+
+			apiResponse, responseBody := getSummary(t, "period_type=all")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, helper.Money(110000), responseBody.Results[0].TotalAmount)
+		})
+
+		t.Run("should return 400 if the range is invalid", func(t *testing.T) {
+			insertSpendings()
+
+			for _, query := range []string{
+				"period_start=2025-13-01&period_end=2025-12-31",
+				"period_start=2025-07-31&period_end=2025-07-01",
+				"period_start=2025-07-01",
+				"period_type=custom",
+				"period_type=monthly&period_start=2025-07-01&period_end=2025-07-31",
+			} {
+				apiResponse, _ := getSummary(t, query)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode, query)
+			}
+		})
+	})
+
+	t.Run("GET /v1/spending/timeseries", func(t *testing.T) {
+		getTimeseries := func(t *testing.T, query string) (*http.Response, *response.SpendingTimeseries) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/timeseries?"+query, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.SpendingTimeseries `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, &responseBody.Data
+		}
+
+		day := func(offset int) time.Time {
+			now := time.Now()
+			return time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.Local).AddDate(0, 0, offset)
+		}
+
+		t.Run("should return one point per day with zeros for empty days", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Food", Name: "Bakso", Amount: helper.Money(15000), Datetime: day(-6), IsConfirm: true},
+				&model.Spending{Category: "Transport", Name: "Bensin", Amount: helper.Money(100000), Datetime: day(-6), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Sate", Amount: helper.Money(30000), Datetime: day(-2), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Pending", Amount: helper.Money(99000), Datetime: day(-2)},
+			)
+
+			apiResponse, timeseries := getTimeseries(t, "bucket=day&period_start="+
+				day(-6).Format(time.DateOnly)+"&period_end="+day(0).Format(time.DateOnly))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, timeseries.Points, 7)
+			assert.Equal(t, helper.Money(115000), timeseries.Points[0].TotalAmount)
+			assert.Equal(t, helper.Money(0), timeseries.Points[1].TotalAmount)
+			assert.Equal(t, helper.Money(30000), timeseries.Points[4].TotalAmount)
+			assert.Equal(t, helper.Money(145000), timeseries.Total)
+		})
+
+		t.Run("should only count the requested category", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			food := &model.Spending{Category: "Food", Name: "Bakso", Amount: helper.Money(15000), Datetime: day(-1), IsConfirm: true}
+			helper.InsertSpending(test.DB, fixture.UserOne, food,
+				&model.Spending{Category: "Transport", Name: "Bensin", Amount: helper.Money(100000), Datetime: day(-1), IsConfirm: true},
+			)
+
+			apiResponse, timeseries := getTimeseries(t, "bucket=month&category_id="+food.CategoryID.String()+
+				"&period_start="+day(-1).Format(time.DateOnly)+"&period_end="+day(0).Format(time.DateOnly))
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(15000), timeseries.Total)
+		})
+
+		t.Run("should return 400 if the bucket or range is invalid", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			for _, query := range []string{
+				"bucket=year&period_start=2025-01-01&period_end=2025-12-31",
+				"bucket=day&period_start=2025-01-01",
+				"bucket=day&period_start=2020-01-01&period_end=2025-12-31",
+				"bucket=day&period_start=2025-02-01&period_end=2025-01-01",
+			} {
+				apiResponse, _ := getTimeseries(t, query)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode, query)
+			}
+		})
+	})
+
+	t.Run("GET /v1/spending/comparison", func(t *testing.T) {
+		getComparison := func(t *testing.T, query string) (*http.Response, *response.SpendingComparison) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/comparison?"+query, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.SpendingComparison `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, &responseBody.Data
+		}
+
+		t.Run("should compare each category with the previous month", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne,
+				&model.Spending{Category: "Food", Name: "Bakso", Amount: helper.Money(100000),
+					Datetime: time.Date(2025, 6, 10, 12, 0, 0, 0, time.Local), IsConfirm: true},
+				&model.Spending{Category: "Food", Name: "Sate", Amount: helper.Money(150000),
+					Datetime: time.Date(2025, 7, 3, 12, 0, 0, 0, time.Local), IsConfirm: true},
+				&model.Spending{Category: "Transport", Name: "Bensin", Amount: helper.Money(50000),
+					Datetime: time.Date(2025, 7, 20, 12, 0, 0, 0, time.Local), IsConfirm: true},
+				&model.Spending{Category: "Other", Name: "Topi", Amount: helper.Money(40000),
+					Datetime: time.Date(2025, 6, 30, 12, 0, 0, 0, time.Local), IsConfirm: true},
+			)
+
+			apiResponse, comparison := getComparison(t, "period_type=monthly&date=2025-07-15")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(200000), comparison.CurrentTotal)
+			assert.Equal(t, helper.Money(140000), comparison.PreviousTotal)
+			assert.Equal(t, helper.Money(60000), comparison.Change)
+			assert.Len(t, comparison.Categories, 3)
+
+			food := comparison.Categories[0]
+			assert.Equal(t, "Food", food.Category)
+			assert.Equal(t, helper.Money(50000), food.Change)
+			assert.InDelta(t, 50.0, *food.ChangePercent, 0.001)
+			assert.InDelta(t, 75.0, food.Share, 0.001)
+			assert.Equal(t, "up", food.Trend)
+
+			transport := comparison.Categories[1]
+			assert.Equal(t, "new", transport.Trend)
+			assert.Nil(t, transport.ChangePercent)
+
+			other := comparison.Categories[2]
+			assert.Equal(t, helper.Money(0), other.CurrentTotal)
+			assert.Equal(t, "down", other.Trend)
+			assert.InDelta(t, -100.0, *other.ChangePercent, 0.001)
+		})
+
+		t.Run("should return 400 if period_type is not weekly, monthly or yearly", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			apiResponse, _ := getComparison(t, "period_type=daily")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("Spending timezone", func(t *testing.T) {
+		// 20:00 UTC on July 1st is 03:00 on July 2nd in Jakarta (UTC+7)
+		at := time.Date(2025, 7, 1, 20, 0, 0, 0, time.UTC)
+
+		insertWIBUser := func() *model.User {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			user := &model.User{
+				Name: "WIB", Email: "wib@gmail.com", Password: "password1", Role: "user", Timezone: "Asia/Jakarta",
+			}
+			helper.InsertUser(test.DB, user)
+			helper.InsertSpending(test.DB, user,
+				&model.Spending{Category: "Food", Name: "Sahur", Amount: helper.Money(20000), Datetime: at, IsConfirm: true},
+			)
+			return user
+		}
+
+		getTotal := func(t *testing.T, user *model.User, query, timezone string) (*http.Response, model.Money) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/summary/total?"+query, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+			if timezone != "" {
+				request.Header.Set("X-Timezone", timezone)
+			}
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.TotalSummarySpending `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, responseBody.Data.Total
+		}
+
+		t.Run("should keep summaries in the user's timezone", func(t *testing.T) {
+			user := insertWIBUser()
+
+			summaries, err := helper.GetSpendingSummary(test.DB, user.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+
+			jakarta, _ := time.LoadLocation("Asia/Jakarta")
+			assert.True(t, time.Date(2025, 7, 2, 0, 0, 0, 0, jakarta).Equal(summaries[0].PeriodStart))
+
+			apiResponse, total := getTotal(t, user, "period_start=2025-07-02&period_end=2025-07-02", "")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(20000), total)
+		})
+
+		t.Run("should compute the range in the X-Timezone header", func(t *testing.T) {
+			user := insertWIBUser()
+
+			apiResponse, total := getTotal(t, user, "period_start=2025-07-01&period_end=2025-07-01", "UTC")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(20000), total)
+
+			apiResponse, total = getTotal(t, user, "period_start=2025-07-02&period_end=2025-07-02", "UTC")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, helper.Money(0), total)
+		})
+
+		t.Run("should return 400 if the X-Timezone header is not a timezone", func(t *testing.T) {
+			user := insertWIBUser()
+
+			apiResponse, _ := getTotal(t, user, "period_start=2025-07-01&period_end=2025-07-01", "Mars/Olympus")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should move summaries when the user changes timezone", func(t *testing.T) {
+			user := insertWIBUser()
+
+			timezone := "UTC"
+			bodyJSON, err := json.Marshal(validation.UpdateUser{Timezone: &timezone})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/users/"+user.ID.String(), strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, user.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.True(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC).Equal(summaries[0].PeriodStart))
+		})
+
+		t.Run("should move summaries to the default timezone when the user resets it", func(t *testing.T) {
+			user := insertWIBUser()
+
+			timezone := ""
+			apiResponse, _ := helper.SendJSON(t, user, http.MethodPatch, "/v1/users/"+user.ID.String(),
+				validation.UpdateUser{Timezone: &timezone})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			stored := new(model.User)
+			assert.Nil(t, test.DB.First(stored, "id = ?", user.ID).Error)
+			assert.Equal(t, "", stored.Timezone)
+
+			loc, err := service.LoadLocation("")
+			assert.Nil(t, err)
+			local := at.In(loc)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, user.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.True(t, time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).Equal(summaries[0].PeriodStart))
+		})
+	})
+
+	t.Run("Spending currency", func(t *testing.T) {
+		july := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		createSGD := func(t *testing.T, user *model.User, text string) (*http.Response, *response.CreateSpending) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", text))
+			assert.Nil(t, writer.WriteField("currency", "SGD"))
+			assert.Nil(t, writer.WriteField("datetime", "2025-07-15T12:00:00Z"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, user))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.CreateSpending `json:"data"`
+			})
+			assert.Nil(t, json.Unmarshal(bytes, responseBody))
+
+			return apiResponse, &responseBody.Data
+		}
+
+		t.Run("should keep the original amount and total the base amount", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12000", july)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "13000", july.AddDate(0, 1, 0))
+
+			apiResponse, spending := createSGD(t, fixture.UserOne, "nasi lemak 12.5")
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, model.NewMoney(1250, "SGD"), spending.Amount)
+			assert.Equal(t, helper.Money(150000), spending.BaseAmount)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, "12000", stored.ExchangeRate)
+
+			// Extracted spendings are pending, confirming them adds the base amount to the summaries
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending/"+spending.ID.String()+"/confirm", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, spending.Category, "monthly")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, helper.Money(150000), summaries[0].TotalAmount)
+		})
+
+		t.Run("should return 422 if there is no rate on the transaction date", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12000", july.AddDate(0, 1, 0))
+
+			apiResponse, _ := createSGD(t, fixture.UserOne, "nasi lemak 12.5")
+			assert.Equal(t, http.StatusUnprocessableEntity, apiResponse.StatusCode)
+		})
+
+		t.Run("should convert spendings when the user changes base currency", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12500", july)
+			spending := &model.Spending{
+				Category: "Food", Name: "Nasi", Amount: helper.Money(25000), Datetime: july.AddDate(0, 0, 14), IsConfirm: true,
+			}
+			helper.InsertSpending(test.DB, fixture.UserOne, spending)
+
+			currency := "SGD"
+			bodyJSON, err := json.Marshal(validation.UpdateUser{BaseCurrency: &currency})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/users/"+fixture.UserOne.ID.String(), strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), stored.Amount)
+			assert.Equal(t, model.NewMoney(200, "SGD"), stored.BaseAmount)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "monthly")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, model.NewMoney(200, "SGD"), summaries[0].TotalAmount)
+		})
+
+		t.Run("should convert spendings back when the user resets the base currency", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12500", july)
+			spending := &model.Spending{
+				Category: "Food", Name: "Nasi", Amount: helper.Money(25000), Datetime: july.AddDate(0, 0, 14), IsConfirm: true,
+			}
+			helper.InsertSpending(test.DB, fixture.UserOne, spending)
+
+			for _, currency := range []string{"SGD", ""} {
+				apiResponse, _ := helper.SendJSON(t, fixture.UserOne, http.MethodPatch, "/v1/users/"+fixture.UserOne.ID.String(),
+					validation.UpdateUser{BaseCurrency: &currency})
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode, currency)
+			}
+
+			user := new(model.User)
+			assert.Nil(t, test.DB.First(user, "id = ?", fixture.UserOne.ID).Error)
+			assert.Equal(t, "", user.BaseCurrency)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), stored.BaseAmount)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "monthly")
+			assert.Nil(t, err)
+			assert.Len(t, summaries, 1)
+			assert.Equal(t, helper.Money(25000), summaries[0].TotalAmount)
+		})
+
+		t.Run("should keep the base currency if a spending cannot be converted", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			currency := "JPY"
+			bodyJSON, err := json.Marshal(validation.UpdateUser{BaseCurrency: &currency})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/users/"+fixture.UserOne.ID.String(), strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, apiResponse.StatusCode)
+
+			user := new(model.User)
+			assert.Nil(t, test.DB.First(user, "id = ?", fixture.UserOne.ID).Error)
+			assert.Equal(t, "", user.BaseCurrency)
+		})
+
+		t.Run("should not convert spendings if the rest of the user update fails", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.ClearExchangeRates(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertExchangeRate(test.DB, "SGD", "IDR", "12500", july)
+			spending := &model.Spending{
+				Category: "Food", Name: "Nasi", Amount: helper.Money(25000), Datetime: july.AddDate(0, 0, 14), IsConfirm: true,
+			}
+			helper.InsertSpending(test.DB, fixture.UserOne, spending)
+
+			currency := "SGD"
+			bodyJSON, err := json.Marshal(validation.UpdateUser{BaseCurrency: &currency, Email: fixture.UserTwo.Email})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/users/"+fixture.UserOne.ID.String(), strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+
+			user := new(model.User)
+			assert.Nil(t, test.DB.First(user, "id = ?", fixture.UserOne.ID).Error)
+			assert.Equal(t, "", user.BaseCurrency)
+
+			stored, err := helper.GetSpendingByID(test.DB, spending.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), stored.BaseAmount)
+		})
+	})
+
+	t.Run("Spending ownership", func(t *testing.T) {
+		t.Run("should only list spendings of the calling user", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			helper.InsertSpending(test.DB, fixture.UserTwo, fixture.SpendingOther)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/list", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserTwo))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[model.Spending])
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+			assert.Equal(t, fixture.SpendingOther.ID, responseBody.Results[0].ID)
+		})
+
+		t.Run("should return 404 when reading, updating or deleting another user's spending", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			for _, method := range []string{http.MethodGet, http.MethodPatch, http.MethodDelete} {
+				request := httptest.NewRequest(method, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+					strings.NewReader(`{"amount": 1}`))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserTwo))
+
+				apiResponse, err := test.App.Test(request)
+				assert.Nil(t, err)
+
+				assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+			}
+
+			spending, err := helper.GetSpendingByID(test.DB, fixture.SpendingOne.ID.String())
+			assert.Nil(t, err)
+			assert.Equal(t, fixture.SpendingOne.Amount, spending.Amount)
+		})
+
+		t.Run("should not include other users in summaries", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			helper.InsertSpending(test.DB, fixture.UserTwo, fixture.SpendingOther)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/summary", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserTwo))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[response.SummarySpending])
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, fixture.SpendingOther.Amount, responseBody.Results[0].TotalAmount)
+		})
+	})
+
+	t.Run("GET /v1/spending/:spendingId/attachments", func(t *testing.T) {
+		t.Run("should return 200 and list then download the receipt", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			attachment := helper.InsertAttachment(test.DB, fixture.SpendingOne, "receipt.pdf", []byte("%PDF-1.4 receipt"))
+
+			url := "/v1/spending/" + fixture.SpendingOne.ID.String() + "/attachments"
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Contains(t, string(bytes), attachment.ID.String())
+			assert.NotContains(t, string(bytes), "storage_key")
+
+			request = httptest.NewRequest(http.MethodGet, url+"/"+attachment.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err = io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "application/pdf", apiResponse.Header.Get("Content-Type"))
+			assert.Equal(t, "%PDF-1.4 receipt", string(bytes))
+		})
+
+		t.Run("should return 404 for another user's spending", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			helper.InsertAttachment(test.DB, fixture.SpendingOne, "receipt.pdf", []byte("%PDF-1.4 receipt"))
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/"+fixture.SpendingOne.ID.String()+"/attachments", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserTwo))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("PATCH /v1/spending/:spendingId", func(t *testing.T) {
+		t.Run("should return 200 and move the amount delta into every summary period", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			bodyJSON, err := json.Marshal(validation.UpdateSpending{Amount: "2500"})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("Content-Type", "application/json")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.SpendingOne.UserSessionID, "Food", periodType)
+				assert.Nil(t, err)
+				assert.Len(t, summaries, 1)
+				assert.Equal(t, helper.Money(2500), summaries[0].TotalAmount)
+			}
+		})
+
+		t.Run("should move the amount to the new category", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			bodyJSON, err := json.Marshal(validation.UpdateSpending{Category: "Drink"})
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("Content-Type", "application/json")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			oldSummaries, err := helper.GetSpendingSummary(test.DB, fixture.SpendingOne.UserSessionID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(0), oldSummaries[0].TotalAmount)
+
+			newSummaries, err := helper.GetSpendingSummary(test.DB, fixture.SpendingOne.UserSessionID, "Drink", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(25000), newSummaries[0].TotalAmount)
+		})
+
+		t.Run("should return 400 if request body is empty", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			request := httptest.NewRequest(http.MethodPatch, "/v1/spending/"+fixture.SpendingOne.ID.String(),
+				strings.NewReader("{}"))
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("Content-Type", "application/json")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("Pending spendings", func(t *testing.T) {
+		pendingSpending := func() *model.Spending {
+			return &model.Spending{Category: "Drink", Name: "Kopi susu", Amount: helper.Money(25000), Datetime: time.Now()}
+		}
+
+		t.Run("should create extracted spendings as pending and keep them out of summaries", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "kopi susu 25rb"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			spendings, err := helper.GetSpendingsBySession(test.DB, fixture.UserOne.ID)
+			assert.Nil(t, err)
+			assert.Len(t, spendings, 1)
+			assert.False(t, spendings[0].IsConfirm)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Drink", "daily")
+			assert.Nil(t, err)
+			assert.Empty(t, summaries)
+		})
+
+		t.Run("should list only pending spendings", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			pending := pendingSpending()
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne, pending)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/spending/pending", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[model.Spending])
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+			assert.Equal(t, pending.ID, responseBody.Results[0].ID)
+		})
+
+		t.Run("should confirm with corrections and add the spending to every summary period", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			pending := pendingSpending()
+			helper.InsertSpending(test.DB, fixture.UserOne, pending)
+
+			requestBody := validation.UpdateSpending{Amount: "18000"}
+			bodyJSON, err := json.Marshal(requestBody)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending/"+pending.ID.String()+"/confirm",
+				strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			spending, err := helper.GetSpendingByID(test.DB, pending.ID.String())
+			assert.Nil(t, err)
+			assert.True(t, spending.IsConfirm)
+			assert.Equal(t, helper.Money(18000), spending.Amount)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Drink", periodType)
+				assert.Nil(t, err)
+				assert.Equal(t, helper.Money(18000), summaries[0].TotalAmount)
+			}
+		})
+
+		t.Run("should return 409 when confirming or rejecting a confirmed spending", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			for _, action := range []string{"confirm", "reject"} {
+				request := httptest.NewRequest(http.MethodPost,
+					"/v1/spending/"+fixture.SpendingOne.ID.String()+"/"+action, nil)
+				request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+				apiResponse, err := test.App.Test(request)
+				assert.Nil(t, err)
+
+				assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+			}
+		})
+
+		t.Run("should delete a rejected spending", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			pending := pendingSpending()
+			helper.InsertSpending(test.DB, fixture.UserOne, pending)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending/"+pending.ID.String()+"/reject", nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			_, err = helper.GetSpendingByID(test.DB, pending.ID.String())
+			assert.NotNil(t, err)
+		})
+	})
+
+	t.Run("Summary upserts", func(t *testing.T) {
+		t.Run("should keep one row per period when spendings are created concurrently", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			category := &model.Category{Name: "Food"}
+			assert.Nil(t, test.DB.FirstOrCreate(category, model.Category{Name: "Food"}).Error)
+
+			const workers = 20
+			at := time.Now()
+
+			var wg sync.WaitGroup
+			errs := make(chan error, workers)
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- test.DB.Transaction(func(tx *gorm.DB) error {
+						return service.UpsertSummary(tx, fixture.UserOne.ID, category.ID, category.Name, helper.Money(1000), at)
+					})
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				assert.Nil(t, err)
+			}
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", periodType)
+				assert.Nil(t, err)
+				assert.Len(t, summaries, 1)
+				assert.Equal(t, helper.Money(int64(workers)*1000), summaries[0].TotalAmount)
+			}
+		})
+	})
+
+	t.Run("Summary worker", func(t *testing.T) {
+		t.Run("should apply the summaries queued by a confirmed spending", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertCategory(test.DB, "Transport")
+
+			autoConfirm := config.ExpenseAutoConfirm
+			config.ExpenseAutoConfirm = 0.5
+			defer func() { config.ExpenseAutoConfirm = autoConfirm }()
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			assert.Nil(t, writer.WriteField("text", "bensin 100k"))
+			assert.Nil(t, writer.Close())
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/spending", &body)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+			request.Header.Set("X-Expense-Extractor", "offline")
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			var events []model.OutboxEvent
+			assert.Nil(t, test.DB.Find(&events).Error)
+			assert.Len(t, events, 1)
+			assert.Equal(t, service.EventSpendingCreated, events[0].Type)
+			assert.Equal(t, service.OutboxPending, events[0].Status)
+
+			processed, err := test.SummaryWorker.ProcessPending(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, processed)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Transport", periodType)
+				assert.Nil(t, err)
+				assert.Equal(t, helper.Money(100000), summaries[0].TotalAmount)
+			}
+
+			assert.Nil(t, test.DB.First(&events[0], "id = ?", events[0].ID).Error)
+			assert.Equal(t, service.OutboxProcessed, events[0].Status)
+		})
+
+		t.Run("should schedule a retry when an event fails", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+
+			event := &model.OutboxEvent{Type: "unknown", Payload: []byte(`{}`)}
+			assert.Nil(t, test.DB.Create(event).Error)
+
+			processed, err := test.SummaryWorker.ProcessPending(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, processed)
+
+			assert.Nil(t, test.DB.First(event, "id = ?", event.ID).Error)
+			assert.Equal(t, service.OutboxPending, event.Status)
+			assert.Equal(t, 1, event.Attempts)
+			assert.NotEmpty(t, event.LastError)
+			assert.True(t, event.AvailableAt.After(time.Now()))
+		})
+	})
+
+	t.Run("DELETE /v1/spending/:spendingId", func(t *testing.T) {
+		t.Run("should return 200 and remove the spending from every summary period", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/spending/"+fixture.SpendingOne.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.Common)
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "success", responseBody.Status)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.SpendingOne.UserSessionID, "Food", periodType)
+				assert.Nil(t, err)
+				assert.Equal(t, helper.Money(0), summaries[0].TotalAmount)
+			}
+		})
+
+		t.Run("should remove the spending's attachment files", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			attachment := helper.InsertAttachment(test.DB, fixture.SpendingOne, "receipt.pdf", []byte("%PDF-1.4 receipt"))
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/spending/"+fixture.SpendingOne.ID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			_, err = os.Stat(filepath.Join(config.AttachmentDir, attachment.StorageKey))
+			assert.True(t, os.IsNotExist(err))
+		})
+
+		t.Run("should return 404 if spending is not found", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/spending/"+uuid.NewString(), nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken(t, fixture.UserOne))
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
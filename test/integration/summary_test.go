@@ -0,0 +1,159 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryRoutes(t *testing.T) {
+	t.Run("POST /v1/summaries/rebuild", func(t *testing.T) {
+		rebuild := func(t *testing.T, user *model.User, requestBody validation.RebuildSummaries) *http.Response {
+			bodyJSON, err := json.Marshal(requestBody)
+			assert.Nil(t, err)
+
+			token, err := fixture.AccessToken(user)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/summaries/rebuild", strings.NewReader(string(bodyJSON)))
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			return apiResponse
+		}
+
+		// drift corrupts one summary row and adds a row no spending backs
+		drift := func(t *testing.T) {
+			assert.Nil(t, test.DB.Model(&model.CategorySpendingSummary{}).
+				Where("user_session_id = ? AND category = ? AND period_type = ?", fixture.UserOne.ID, "Food", "daily").
+				Update("total_amount_minor", 1).Error)
+
+			assert.Nil(t, service.UpsertSummary(test.DB, fixture.UserOne.ID, uuid.New(), "Ghost", helper.Money(5000),
+				time.Now().AddDate(-1, 0, 0)))
+		}
+
+		t.Run("should return 200 and report the drift without writing on a dry run", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			drift(t)
+
+			apiResponse := rebuild(t, fixture.Admin, validation.RebuildSummaries{
+				UserID: fixture.UserOne.ID.String(),
+				DryRun: true,
+			})
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(struct {
+				Data response.RebuildSummaries `json:"data"`
+			})
+
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.True(t, responseBody.Data.DryRun)
+			assert.Equal(t, 1, responseBody.Data.Spendings)
+			assert.Equal(t, 1, responseBody.Data.Updated)
+			assert.Equal(t, 4, responseBody.Data.Deleted)
+			assert.Equal(t, 3, responseBody.Data.Unchanged)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), summaries[0].TotalAmount.Minor)
+		})
+
+		t.Run("should return 200 and rewrite the summaries from the spendings", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+			drift(t)
+
+			apiResponse := rebuild(t, fixture.Admin, validation.RebuildSummaries{})
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			for _, periodType := range []string{"daily", "weekly", "monthly", "yearly"} {
+				summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", periodType)
+				assert.Nil(t, err)
+				assert.Equal(t, helper.Money(25000), summaries[0].TotalAmount)
+
+				ghosts, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Ghost", periodType)
+				assert.Nil(t, err)
+				assert.Empty(t, ghosts)
+			}
+		})
+
+		t.Run("should leave out spendings the summary worker has not applied yet", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.ClearSpendings(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.Admin)
+			helper.InsertSpending(test.DB, fixture.UserOne, fixture.SpendingOne)
+
+			// A confirmed spending whose summary event is still queued
+			queued := &model.Spending{Category: "Food", Name: "Bakso", Amount: helper.Money(15000), Datetime: time.Now()}
+			helper.InsertSpending(test.DB, fixture.UserOne, queued)
+			payload, err := json.Marshal(service.SpendingCreatedEvent{
+				SpendingID:    queued.ID,
+				UserSessionID: queued.UserSessionID,
+				CategoryID:    *queued.CategoryID,
+				Category:      queued.Category,
+				Amount:        queued.Amount,
+				Datetime:      queued.Datetime,
+			})
+			assert.Nil(t, err)
+			assert.Nil(t, test.DB.Create(&model.OutboxEvent{Type: service.EventSpendingCreated, Payload: payload}).Error)
+			assert.Nil(t, test.DB.Model(queued).Update("is_confirm", true).Error)
+
+			apiResponse := rebuild(t, fixture.Admin, validation.RebuildSummaries{})
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			_, err = test.SummaryWorker.ProcessPending(context.Background())
+			assert.Nil(t, err)
+
+			summaries, err := helper.GetSpendingSummary(test.DB, fixture.UserOne.ID, "Food", "daily")
+			assert.Nil(t, err)
+			assert.Equal(t, helper.Money(40000), summaries[0].TotalAmount)
+		})
+
+		t.Run("should return 403 if the user is not an admin", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			apiResponse := rebuild(t, fixture.UserOne, validation.RebuildSummaries{DryRun: true})
+
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the user id is not a valid uuid", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			apiResponse := rebuild(t, fixture.Admin, validation.RebuildSummaries{UserID: "not-a-uuid"})
+
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,27 @@
+package model_test
+
+import (
+	"app/src/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCategoryLabel(t *testing.T) {
+	t.Run("should ignore case and accents", func(t *testing.T) {
+		assert.Equal(t, "cafe", model.NormalizeCategoryLabel("Café"))
+		assert.Equal(t, "cafe", model.NormalizeCategoryLabel("CAFÉ"))
+		assert.Equal(t, "creme brulee", model.NormalizeCategoryLabel("Crème Brûlée"))
+	})
+
+	t.Run("should collapse punctuation and spaces", func(t *testing.T) {
+		assert.Equal(t, "makanan minuman", model.NormalizeCategoryLabel("Makanan & Minuman"))
+		assert.Equal(t, "makanan minuman", model.NormalizeCategoryLabel("  makanan/minuman!"))
+		assert.Equal(t, "food", model.NormalizeCategoryLabel("food"))
+	})
+
+	t.Run("should keep digits and return nothing for labels without letters", func(t *testing.T) {
+		assert.Equal(t, "top up 2", model.NormalizeCategoryLabel("Top-up #2"))
+		assert.Equal(t, "", model.NormalizeCategoryLabel(" - & "))
+	})
+}
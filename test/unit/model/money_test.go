@@ -0,0 +1,102 @@
+package model_test
+
+import (
+	"app/src/model"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("Parsing", func(t *testing.T) {
+		t.Run("should read decimals exactly in minor units", func(t *testing.T) {
+			money, err := model.ParseMoney("12500.5", "IDR")
+			assert.NoError(t, err)
+			assert.Equal(t, model.NewMoney(1250050, "IDR"), money)
+
+			money, err = model.ParseMoney("0.07", "USD")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), money.Minor)
+		})
+
+		t.Run("should follow the minor unit of the currency", func(t *testing.T) {
+			money, err := model.ParseMoney("1500", "JPY")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1500), money.Minor)
+
+			_, err = model.ParseMoney("1500.5", "JPY")
+			assert.Error(t, err)
+
+			money, err = model.ParseMoney("1.125", "KWD")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1125), money.Minor)
+		})
+
+		t.Run("should reject amounts that are not decimals", func(t *testing.T) {
+			for _, value := range []string{"", "abc", "1,5", "1.2.3", "1e5", "99999999999999999999"} {
+				_, err := model.ParseMoney(value, "IDR")
+				assert.Error(t, err, value)
+			}
+		})
+	})
+
+	t.Run("Formatting", func(t *testing.T) {
+		t.Run("should format with the currency's decimals", func(t *testing.T) {
+			assert.Equal(t, "12500.50", model.NewMoney(1250050, "IDR").String())
+			assert.Equal(t, "0.05", model.NewMoney(5, "USD").String())
+			assert.Equal(t, "-0.05", model.NewMoney(-5, "USD").String())
+			assert.Equal(t, "1500", model.NewMoney(1500, "JPY").String())
+		})
+
+		t.Run("should round extractor floats to the minor unit", func(t *testing.T) {
+			assert.Equal(t, model.NewMoney(30, "USD"), model.MoneyFromFloat(0.1+0.2, "USD"))
+			assert.Equal(t, model.NewMoney(1250050, "IDR"), model.MoneyFromFloat(12500.5, "IDR"))
+		})
+
+		t.Run("should round trip through JSON", func(t *testing.T) {
+			bytes, err := json.Marshal(model.NewMoney(1250050, "IDR"))
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"value":"12500.50","currency":"IDR","minor_units":1250050}`, string(bytes))
+
+			var money model.Money
+			assert.NoError(t, json.Unmarshal(bytes, &money))
+			assert.Equal(t, model.NewMoney(1250050, "IDR"), money)
+		})
+	})
+
+	t.Run("Converting", func(t *testing.T) {
+		rate := func(value string) *big.Rat {
+			rate, err := model.ParseRate(value)
+			assert.NoError(t, err)
+			return rate
+		}
+
+		t.Run("should convert between minor units of different exponents", func(t *testing.T) {
+			assert.Equal(t, model.NewMoney(15187813, "IDR"), model.NewMoney(1250, "SGD").Convert("IDR", rate("12150.25")))
+			assert.Equal(t, model.NewMoney(13345452, "IDR"), model.NewMoney(1200, "JPY").Convert("IDR", rate("111.2121")))
+			assert.Equal(t, model.NewMoney(1500, "JPY"), model.NewMoney(1000, "USD").Convert("JPY", rate("150")))
+		})
+
+		t.Run("should round half away from zero", func(t *testing.T) {
+			assert.Equal(t, model.NewMoney(3, "USD"), model.NewMoney(5, "EUR").Convert("USD", rate("0.5")))
+			assert.Equal(t, model.NewMoney(-3, "USD"), model.NewMoney(-5, "EUR").Convert("USD", rate("0.5")))
+		})
+
+		t.Run("should keep amounts already in the currency", func(t *testing.T) {
+			assert.Equal(t, model.NewMoney(1250, "SGD"), model.NewMoney(1250, "SGD").Convert("SGD", rate("2")))
+		})
+
+		t.Run("should read and format exchange rates", func(t *testing.T) {
+			assert.Equal(t, "12150.25", model.FormatRate(rate("12150.250")))
+			assert.Equal(t, "0.0000823", model.FormatRate(rate("0.0000823")))
+			assert.Equal(t, "2", model.FormatRate(rate("2.0")))
+
+			for _, value := range []string{"0", "-1", "1/3", "1e5", "0.0000000000001"} {
+				_, err := model.ParseRate(value)
+				assert.Error(t, err, value)
+			}
+		})
+	})
+}
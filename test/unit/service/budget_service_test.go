@@ -0,0 +1,31 @@
+package service_test
+
+import (
+	"app/src/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectSpending(t *testing.T) {
+	start := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should extend the spending linearly to the end of the period", func(t *testing.T) {
+		assert.Equal(t, int64(3000000), service.ProjectSpending(1000000, start, end, start.AddDate(0, 0, 10)))
+		assert.Equal(t, int64(1500000), service.ProjectSpending(750000, start, end, start.AddDate(0, 0, 15)))
+	})
+
+	t.Run("should keep the spending once the period ended", func(t *testing.T) {
+		assert.Equal(t, int64(1000000), service.ProjectSpending(1000000, start, end, end))
+		assert.Equal(t, int64(1000000), service.ProjectSpending(1000000, start, end, end.Add(time.Hour)))
+	})
+
+	t.Run("should count at least a day as elapsed", func(t *testing.T) {
+		assert.Equal(t, int64(0), service.ProjectSpending(0, start, end, start))
+		assert.Equal(t, int64(750000), service.ProjectSpending(25000, start, end, start))
+		assert.Equal(t, int64(750000), service.ProjectSpending(25000, start, end, start.Add(time.Second)))
+		assert.Equal(t, int64(750000), service.ProjectSpending(25000, start, end, start.Add(-time.Hour)))
+	})
+}
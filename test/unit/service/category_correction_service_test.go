@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestCategory(t *testing.T) {
+	drink := &model.Category{ID: uuid.New(), Name: "Drink", IsActive: true}
+	food := &model.Category{ID: uuid.New(), Name: "Food", IsActive: true}
+
+	corrected := func(rawText string, category *model.Category) model.CategoryCorrection {
+		return model.CategoryCorrection{RawText: rawText, CategoryID: category.ID, Category: category}
+	}
+
+	t.Run("should suggest the category similar names were consistently corrected to", func(t *testing.T) {
+		corrections := []model.CategoryCorrection{
+			corrected("Kopi susu gula aren", drink),
+			corrected("kopi susu", drink),
+			corrected("nasi goreng", food),
+		}
+
+		assert.Equal(t, drink, service.SuggestCategory(corrections, "kopi susu"))
+		assert.Equal(t, drink, service.SuggestCategory(corrections, "KOPI SÜSU aren"))
+	})
+
+	t.Run("should not suggest after a single correction", func(t *testing.T) {
+		corrections := []model.CategoryCorrection{corrected("kopi susu", drink)}
+
+		assert.Nil(t, service.SuggestCategory(corrections, "kopi susu"))
+	})
+
+	t.Run("should not suggest when similar names went to different categories", func(t *testing.T) {
+		corrections := []model.CategoryCorrection{
+			corrected("kopi susu", drink),
+			corrected("kopi susu", drink),
+			corrected("kopi susu", food),
+		}
+
+		assert.Nil(t, service.SuggestCategory(corrections, "kopi susu"))
+	})
+
+	t.Run("should ignore names sharing too few words", func(t *testing.T) {
+		corrections := []model.CategoryCorrection{
+			corrected("mie goreng", drink),
+			corrected("mie goreng", drink),
+		}
+
+		assert.Nil(t, service.SuggestCategory(corrections, "nasi goreng spesial"))
+		assert.Nil(t, service.SuggestCategory(corrections, ""))
+	})
+
+	t.Run("should ignore corrections into deactivated categories", func(t *testing.T) {
+		inactive := &model.Category{ID: uuid.New(), Name: "Jajan", IsActive: false}
+		corrections := []model.CategoryCorrection{
+			corrected("kopi susu", inactive),
+			corrected("kopi susu", inactive),
+		}
+
+		assert.Nil(t, service.SuggestCategory(corrections, "kopi susu"))
+	})
+}
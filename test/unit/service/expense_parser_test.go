@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"app/src/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpenseText(t *testing.T) {
+	t.Run("Amount parsing", func(t *testing.T) {
+		t.Run("should understand rb, ribu, k and jt suffixes", func(t *testing.T) {
+			assert.Equal(t, float64(25000), service.ParseExpenseText("beli nasi goreng 25rb").Amount)
+			assert.Equal(t, float64(15000), service.ParseExpenseText("bakso 15 ribu").Amount)
+			assert.Equal(t, float64(100000), service.ParseExpenseText("bensin 100k").Amount)
+			assert.Equal(t, float64(1500000), service.ParseExpenseText("cicilan motor 1,5jt").Amount)
+			assert.Equal(t, float64(2000000), service.ParseExpenseText("sewa kos 2 juta").Amount)
+		})
+
+		t.Run("should understand Indonesian thousand separators", func(t *testing.T) {
+			assert.Equal(t, float64(5000), service.ParseExpenseText("parkir 5.000").Amount)
+			assert.Equal(t, float64(1250000), service.ParseExpenseText("listrik Rp 1.250.000").Amount)
+			assert.Equal(t, float64(12500.5), service.ParseExpenseText("obat 12.500,50").Amount)
+		})
+
+		t.Run("should prefer an explicit amount over a plain quantity", func(t *testing.T) {
+			expense := service.ParseExpenseText("beli 2 kopi 30rb")
+			assert.Equal(t, float64(30000), expense.Amount)
+			assert.Equal(t, "2 kopi", expense.Name)
+		})
+
+		t.Run("should return nil if the text has no amount", func(t *testing.T) {
+			assert.Nil(t, service.ParseExpenseText("makan siang"))
+		})
+	})
+
+	t.Run("Name and category", func(t *testing.T) {
+		t.Run("should drop filler words from the name", func(t *testing.T) {
+			expense := service.ParseExpenseText("beli nasi goreng 25rb")
+			assert.Equal(t, "nasi goreng", expense.Name)
+			assert.Equal(t, "Food", expense.Category)
+		})
+
+		t.Run("should map keywords to a category", func(t *testing.T) {
+			assert.Equal(t, "Transport", service.ParseExpenseText("bensin 100k").Category)
+			assert.Equal(t, "Transport", service.ParseExpenseText("parkir 5.000").Category)
+			assert.Equal(t, "Drink", service.ParseExpenseText("kopi susu 18rb").Category)
+		})
+
+		t.Run("should fall back to Other for unknown words", func(t *testing.T) {
+			assert.Equal(t, "Other", service.ParseExpenseText("kado ulang tahun 150rb").Category)
+		})
+	})
+
+	t.Run("Confidence", func(t *testing.T) {
+		t.Run("should be high for a known keyword and an explicit amount", func(t *testing.T) {
+			assert.GreaterOrEqual(t, service.ParseExpenseText("bensin 100k").Confidence, 0.9)
+		})
+
+		t.Run("should be low for an unknown name and a bare number", func(t *testing.T) {
+			assert.Less(t, service.ParseExpenseText("sesuatu 25000").Confidence, 0.7)
+		})
+	})
+}
+
+func TestParseExpenseLines(t *testing.T) {
+	t.Run("should return every line as a receipt item", func(t *testing.T) {
+		expense := service.ParseExpenseLines("beras 65rb\ntelur 28rb; sabun 12rb")
+
+		assert.Len(t, expense.Items, 3)
+		assert.Equal(t, float64(105000), expense.Amount)
+		assert.Equal(t, "beras", expense.Items[0].Name)
+		assert.Equal(t, "Groceries", expense.Items[0].Category)
+		assert.Equal(t, "Other", expense.Items[2].Category)
+		assert.Equal(t, service.ParseExpenseText("sabun 12rb").Confidence, expense.Confidence)
+	})
+
+	t.Run("should parse a single line like ParseExpenseText", func(t *testing.T) {
+		assert.Equal(t, service.ParseExpenseText("bensin 100k"), service.ParseExpenseLines("bensin 100k"))
+	})
+
+	t.Run("should skip lines without an amount", func(t *testing.T) {
+		expense := service.ParseExpenseLines("belanja mingguan\nberas 65rb\ntelur 28rb")
+
+		assert.Len(t, expense.Items, 2)
+		assert.Equal(t, float64(93000), expense.Amount)
+	})
+}
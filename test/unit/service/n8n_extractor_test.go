@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"app/src/service"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestN8NExtractor(t *testing.T) {
+	t.Run("should return the webhook's expense", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"category":"Food","used":"nasi goreng","total":25000}`))
+		}))
+		defer server.Close()
+
+		expense, err := service.NewN8NExtractor(server.URL, time.Second).
+			Extract(context.Background(), &service.ExpenseInput{Text: "nasi goreng 25rb"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Food", expense.Category)
+		assert.Equal(t, float64(25000), expense.Amount)
+	})
+
+	t.Run("should return 502 if the webhook responds with an error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"Workflow could not be started"}`))
+		}))
+		defer server.Close()
+
+		_, err := service.NewN8NExtractor(server.URL, time.Second).
+			Extract(context.Background(), &service.ExpenseInput{Text: "nasi goreng 25rb"})
+
+		var fiberErr *fiber.Error
+		assert.True(t, errors.As(err, &fiberErr))
+		assert.Equal(t, fiber.StatusBadGateway, fiberErr.Code)
+	})
+
+	t.Run("should return 502 if the webhook response is not an expense", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html>Bad gateway</html>`))
+		}))
+		defer server.Close()
+
+		_, err := service.NewN8NExtractor(server.URL, time.Second).
+			Extract(context.Background(), &service.ExpenseInput{Text: "nasi goreng 25rb"})
+
+		var fiberErr *fiber.Error
+		assert.True(t, errors.As(err, &fiberErr))
+		assert.Equal(t, fiber.StatusBadGateway, fiberErr.Code)
+	})
+
+	t.Run("should return 502 if the webhook does not answer in time", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		_, err := service.NewN8NExtractor(server.URL, 50*time.Millisecond).
+			Extract(context.Background(), &service.ExpenseInput{Text: "nasi goreng 25rb"})
+
+		var fiberErr *fiber.Error
+		assert.True(t, errors.As(err, &fiberErr))
+		assert.Equal(t, fiber.StatusBadGateway, fiberErr.Code)
+	})
+}